@@ -4,6 +4,7 @@ import (
 	"crypto/tls"
 	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
@@ -87,7 +88,96 @@ func main() {
 	engine := app.NewEngine(cfg, version, cfgPath)
 
 	mux := http.NewServeMux()
+	registerRoutes(mux, engine, cfg)
 
+	// v0.3.72: additional studios, each with its own engine and the same API
+	// under /studios/<name>/ (process-wide routes stay with the default studio).
+	studios, errs := engine.LoadStudios()
+	for _, err := range errs {
+		log.Printf("WARN: %v", err)
+	}
+	for _, st := range studios {
+		smux := http.NewServeMux()
+		registerRoutes(smux, st.Engine, st.Config)
+		prefix := "/studios/" + st.Name
+		mux.Handle(prefix+"/", http.StripPrefix(prefix, studioHandler(smux)))
+		log.Printf("studio %s: %s (mode=%s dsp=%s:%d)", st.Name, prefix, st.Config.DSP.Mode, st.Config.DSP.Host, st.Config.DSP.Port)
+	}
+	mux.HandleFunc("/api/studios", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeAPIError(w, http.StatusMethodNotAllowed, "GET required")
+			return
+		}
+		list := []app.StudioSummary{engine.StudioSummary()}
+		for _, st := range studios {
+			list = append(list, st.Engine.StudioSummary())
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"ok": true, "studios": list})
+	})
+
+	addr := cfg.UI.HTTPListen
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	log.Printf("stub-engine %s listening on %s", engine.Version(), addr)
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// studioHandler serves an additional studio's routes, refusing the
+// process-wide ones (see app.StudioGlobalPath).
+func studioHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if app.StudioGlobalPath(r.URL.Path) {
+			writeAPIError(w, http.StatusNotFound, "served by the default studio only: "+r.URL.Path)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// controlGuard applies the server-side write guards for one control and
+// writes the refusal: 401 for bad credentials, 423 for a show lock
+// (v0.3.75), 409 otherwise. It returns the caller so the write can be
+// checked against control policies.
+func controlGuard(w http.ResponseWriter, r *http.Request, engine *app.Engine, ctl string) (app.Identity, bool) {
+	who, err := engine.ResolveIdentity(r)
+	if err != nil {
+		writeAPIError(w, http.StatusUnauthorized, err.Error())
+		return who, false
+	}
+	if err := engine.CheckShowLock(ctl, who); err != nil {
+		writeJSON(w, http.StatusLocked, map[string]any{"ok": false, "error": err.Error(), "showLock": engine.ShowLockStatus()})
+		return who, false
+	}
+	if ok, reason := engine.DSPControlAllowed(ctl, who); !ok {
+		writeAPIError(w, http.StatusConflict, reason)
+		return who, false
+	}
+	return who, true
+}
+
+// policyStatus maps a write error to its HTTP status: 403 for a control
+// policy role refusal, 422 for other policy refusals (v0.3.76), else 400.
+func policyStatus(err error) int {
+	var pe *app.PolicyError
+	if !errors.As(err, &pe) {
+		return http.StatusBadRequest
+	}
+	if pe.Rule == "role" {
+		return http.StatusForbidden
+	}
+	return http.StatusUnprocessableEntity
+}
+
+// registerRoutes registers one studio's API on mux. The default studio is
+// mounted at the root; additional studios under /studios/<name>/ (v0.3.72).
+func registerRoutes(mux *http.ServeMux, engine *app.Engine, cfg *app.Config) {
 	// Health
 	//
 	// This endpoint is used by:
@@ -323,7 +413,7 @@ func main() {
 			//
 			// The watchdog is responsible for observing this flag and restarting the
 			// stub-engine service.
-			_ = app.RequestEngineRestart("config saved via Engineering UI")
+			_ = engine.RequestRestart("config saved via Engineering UI")
 
 			_ = json.NewEncoder(w).Encode(map[string]any{
 				"ok":   true,
@@ -412,12 +502,17 @@ func main() {
 		// v0.2.46 defense-in-depth: server-side DSP control guard.
 		// The UI already blocks control attempts when DISCONNECTED, but we also
 		// enforce it here to protect against stale cached JS or non-UI clients.
-		if ok, reason := engine.DSPControlAllowed(); !ok {
-			writeAPIError(w, http.StatusConflict, reason)
+		who, ok := controlGuard(w, r, engine, idStr)
+		if !ok {
 			return
 		}
-		if err := engine.SetRC(idStr, body.Value); err != nil {
-			writeAPIError(w, http.StatusBadRequest, err.Error())
+		// v0.3.65: mock scenarios may simulate failed writes.
+		if err := engine.SimulatedWriteError(); err != nil {
+			writeAPIError(w, http.StatusBadRequest, "dsp write failed: "+err.Error())
+			return
+		}
+		if err := engine.SetRC(idStr, body.Value, who); err != nil {
+			writeAPIError(w, policyStatus(err), err.Error())
 			return
 		}
 		w.WriteHeader(http.StatusNoContent)
@@ -476,16 +571,16 @@ func main() {
 			return
 		}
 		// Defense-in-depth: keep the same DSP control guard used by /api/rc.
-		if ok, reason := engine.DSPControlAllowed(); !ok {
-			writeAPIError(w, http.StatusConflict, reason)
+		who, ok := controlGuard(w, r, engine, "STUB_SPK_MUTE")
+		if !ok {
 			return
 		}
 		src := strings.TrimSpace(body.Source)
 		if src == "" {
 			src = "ui"
 		}
-		if err := engine.ApplySpeakerMuteIntent(*body.Mute, src); err != nil {
-			writeAPIError(w, http.StatusBadRequest, err.Error())
+		if err := engine.ApplySpeakerMuteIntent(*body.Mute, src, who); err != nil {
+			writeAPIError(w, policyStatus(err), err.Error())
 			return
 		}
 		w.WriteHeader(http.StatusNoContent)
@@ -508,12 +603,252 @@ func main() {
 			writeAPIError(w, http.StatusMethodNotAllowed, "POST required")
 			return
 		}
-		// Single-shot test only. Timeout is conservative and fixed here.
-		snap := engine.TestDSPConnectivity(1200 * time.Millisecond)
+		// Single-shot test only. Timeout comes from dsp.health.timeout_ms.
+		snap := engine.TestDSPConnectivity(0)
 		w.Header().Set("Content-Type", "application/json")
 		_ = json.NewEncoder(w).Encode(snap)
 	})
 
+	// v0.3.75: show lock (on-air write freeze). See internal/show_lock.go.
+	mux.HandleFunc("/api/show/lock", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, engine.ShowLockStatus())
+			return
+		case http.MethodPost:
+		default:
+			writeAPIError(w, http.StatusMethodNotAllowed, "GET/POST required")
+			return
+		}
+		who, err := engine.ResolveIdentity(r)
+		if err != nil {
+			writeAPIError(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		var body app.ShowLockRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeAPIError(w, http.StatusBadRequest, "bad json")
+			return
+		}
+		l, err := engine.LockShow(body, who)
+		if err != nil {
+			status := http.StatusBadRequest
+			var le *app.ShowLockError
+			switch {
+			case errors.As(err, &le):
+				status = http.StatusLocked
+			case !app.RoleAtLeast(who.Role, app.RoleOperator):
+				status = http.StatusForbidden
+			}
+			writeAPIError(w, status, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, l)
+	})
+	mux.HandleFunc("/api/show/unlock", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeAPIError(w, http.StatusMethodNotAllowed, "POST required")
+			return
+		}
+		who, err := engine.ResolveIdentity(r)
+		if err != nil {
+			writeAPIError(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		var body struct {
+			Reason string `json:"reason"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		l, err := engine.UnlockShow(body.Reason, who)
+		if err != nil {
+			writeJSON(w, http.StatusLocked, map[string]any{"ok": false, "error": err.Error(), "showLock": l})
+			return
+		}
+		writeJSON(w, http.StatusOK, l)
+	})
+
+	// v0.3.74: time-limited LIVE arming (admin). GET is read-only status.
+	mux.HandleFunc("/api/dsp/arm", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, engine.DSPArmStatus())
+			return
+		case http.MethodPost:
+		default:
+			writeAPIError(w, http.StatusMethodNotAllowed, "GET/POST required")
+			return
+		}
+		who, err := engine.ResolveIdentity(r)
+		if err != nil {
+			writeAPIError(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		if !app.RoleAtLeast(who.Role, app.RoleAdmin) {
+			writeAPIError(w, http.StatusForbidden, "admin required")
+			return
+		}
+		var body app.DSPArmRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeAPIError(w, http.StatusBadRequest, "bad json")
+			return
+		}
+		st, err := engine.ArmDSPLive(body, who)
+		if err != nil {
+			status := http.StatusConflict
+			var ae *app.DSPArmError
+			if errors.As(err, &ae) && ae.BadRequest() {
+				status = http.StatusBadRequest
+			}
+			writeAPIError(w, status, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, st)
+	})
+	mux.HandleFunc("/api/dsp/disarm", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeAPIError(w, http.StatusMethodNotAllowed, "POST required")
+			return
+		}
+		who, err := engine.ResolveIdentity(r)
+		if err != nil {
+			writeAPIError(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		if !app.RoleAtLeast(who.Role, app.RoleAdmin) {
+			writeAPIError(w, http.StatusForbidden, "admin required")
+			return
+		}
+		var body struct {
+			Reason string `json:"reason"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		reason := strings.TrimSpace(body.Reason)
+		if reason == "" {
+			reason = "operator"
+		}
+		was := engine.DisarmDSPLive(reason, who)
+		writeJSON(w, http.StatusOK, map[string]any{"ok": true, "wasArmed": was, "arm": engine.DSPArmStatus()})
+	})
+
+	// v0.3.81: design conformance. GET is the last report; POST runs it (admin).
+	mux.HandleFunc("/api/dsp/conformance", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, map[string]any{"ok": true, "report": engine.DSPConformance()})
+			return
+		case http.MethodPost:
+		default:
+			writeAPIError(w, http.StatusMethodNotAllowed, "GET/POST required")
+			return
+		}
+		who, err := engine.ResolveIdentity(r)
+		if err != nil {
+			writeAPIError(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		if !app.RoleAtLeast(who.Role, app.RoleAdmin) {
+			writeAPIError(w, http.StatusForbidden, "admin required")
+			return
+		}
+		// {"writeProbe": true} also checks writability (armed LIVE only).
+		var body struct {
+			WriteProbe bool `json:"writeProbe"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		rep, err := engine.RunDSPConformance("api", body.WriteProbe, who)
+		if err != nil {
+			writeAPIError(w, http.StatusConflict, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"ok": rep.OK, "report": rep})
+	})
+
+	// v0.3.80: admin ECP console (read-only commands; writes need confirm).
+	mux.HandleFunc("/api/dsp/console", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeAPIError(w, http.StatusMethodNotAllowed, "POST required")
+			return
+		}
+		who, err := engine.ResolveIdentity(r)
+		if err != nil {
+			writeAPIError(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		if !app.RoleAtLeast(who.Role, app.RoleAdmin) {
+			writeAPIError(w, http.StatusForbidden, "admin required")
+			return
+		}
+		var body app.ECPConsoleRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeAPIError(w, http.StatusBadRequest, "bad json")
+			return
+		}
+		res, err := engine.ECPConsole(body, who)
+		if err != nil {
+			status := http.StatusBadRequest
+			var ce *app.ECPConsoleError
+			var le *app.ShowLockError
+			switch {
+			case errors.As(err, &le):
+				status = http.StatusLocked
+			case errors.As(err, &ce) && ce.Rule == "confirm":
+				status = http.StatusForbidden
+			case errors.As(err, &ce) && (ce.Rule == "armed" || ce.Rule == "mode"):
+				status = http.StatusConflict
+			case errors.As(err, new(*app.PolicyError)):
+				status = policyStatus(err)
+			}
+			writeAPIError(w, status, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"ok": res.Error == "", "result": res})
+	})
+
+	// v0.3.79: raw ECP transcript (admin). ?format=text|json&download=1 exports;
+	// ?entries=0 returns the recorder status only.
+	mux.HandleFunc("/api/dsp/transcript", func(w http.ResponseWriter, r *http.Request) {
+		who, err := engine.ResolveIdentity(r)
+		if err != nil {
+			writeAPIError(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		if !app.RoleAtLeast(who.Role, app.RoleAdmin) {
+			writeAPIError(w, http.StatusForbidden, "admin required")
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			q := r.URL.Query()
+			st := engine.ECPTranscript(q.Get("entries") != "0")
+			text := strings.EqualFold(q.Get("format"), "text")
+			if q.Get("download") == "1" {
+				ext := "json"
+				if text {
+					ext = "txt"
+				}
+				w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"ecp-transcript-%s.%s\"", time.Now().UTC().Format("20060102-150405"), ext))
+			}
+			if text {
+				w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+				app.WriteECPTranscriptText(w, st)
+				return
+			}
+			writeJSON(w, http.StatusOK, st)
+		case http.MethodPost:
+			var body struct {
+				Enabled bool `json:"enabled"`
+				Clear   bool `json:"clear"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				writeAPIError(w, http.StatusBadRequest, "bad json")
+				return
+			}
+			writeJSON(w, http.StatusOK, engine.SetECPTranscript(body.Enabled, body.Clear, who))
+		default:
+			writeAPIError(w, http.StatusMethodNotAllowed, "GET/POST required")
+		}
+	})
+
 	// Operator-safe reconnect
 
 	mux.HandleFunc("/api/dsp/timeline", func(w http.ResponseWriter, r *http.Request) {
@@ -535,13 +870,174 @@ func main() {
 		_ = json.NewEncoder(w).Encode(engine.ReadDSPTimeline(n))
 	})
 
+	// v0.3.69: outage intervals + availability derived from the DSP timeline.
+	// Query params: ?days=7 (1..90), ?weeks=4 (1..26), ?outages=200 (max 1000)
+	mux.HandleFunc("/api/dsp/outages", func(w http.ResponseWriter, r *http.Request) {
+		days, weeks, maxOut := 7, 4, 200
+		q := r.URL.Query()
+		if v, err := strconv.Atoi(strings.TrimSpace(q.Get("days"))); err == nil {
+			days = v
+		}
+		if v, err := strconv.Atoi(strings.TrimSpace(q.Get("weeks"))); err == nil {
+			weeks = v
+		}
+		if v, err := strconv.Atoi(strings.TrimSpace(q.Get("outages"))); err == nil {
+			maxOut = v
+		}
+		days = min(max(days, 1), 90)
+		weeks = min(max(weeks, 1), 26)
+		maxOut = min(max(maxOut, 0), 1000)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(engine.DSPOutageReport(days, weeks, maxOut))
+	})
+
+	// v0.3.62: audio alarms (read-only).
+	mux.HandleFunc("/api/alarms", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "active": engine.ActiveAlarms()})
+	})
+
+	mux.HandleFunc("/api/alarms/timeline", func(w http.ResponseWriter, r *http.Request) {
+		// Query param: ?n=50 (default 50, max 500)
+		n := 50
+		if v := strings.TrimSpace(r.URL.Query().Get("n")); v != "" {
+			if i, err := strconv.Atoi(v); err == nil {
+				n = i
+			}
+		}
+		if n > 500 {
+			n = 500
+		}
+		if n < 1 {
+			n = 1
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(engine.ReadAlarmTimeline(n))
+	})
+
+	// v0.3.63: over-level review (counts per hour + episodes).
+	// Query params: ?hours=24 (1..168), ?episodes=200 (max 1000)
+	mux.HandleFunc("/api/alarms/overs", func(w http.ResponseWriter, r *http.Request) {
+		hours, maxEp := 24, 200
+		if v, err := strconv.Atoi(strings.TrimSpace(r.URL.Query().Get("hours"))); err == nil {
+			hours = v
+		}
+		if v, err := strconv.Atoi(strings.TrimSpace(r.URL.Query().Get("episodes"))); err == nil {
+			maxEp = v
+		}
+		if hours < 1 {
+			hours = 1
+		}
+		if hours > 168 {
+			hours = 168
+		}
+		if maxEp < 0 {
+			maxEp = 0
+		}
+		if maxEp > 1000 {
+			maxEp = 1000
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(engine.OverReport(hours, maxEp))
+	})
+
+	// v0.3.64: meter trends. ?meter=&from=&to=&res=1s|1m|1h[&format=influx]
+	mux.HandleFunc("/api/meters/history", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeAPIError(w, http.StatusMethodNotAllowed, "GET required")
+			return
+		}
+		q, err := app.ParseMeterHistoryQuery(r.URL.Query())
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		data, err := engine.MeterHistory(q)
+		if err != nil {
+			writeAPIError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if strings.EqualFold(r.URL.Query().Get("format"), "influx") {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			app.WriteMeterHistoryInflux(w, q.Res, data)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"ok":     true,
+			"res":    q.Res,
+			"from":   q.From.UTC().Format(time.RFC3339),
+			"to":     q.To.UTC().Format(time.RFC3339),
+			"units":  "dBFS",
+			"meters": data,
+		})
+	})
+
+	// v0.3.65: mock scenarios. GET is read-only; start/stop require admin.
+	//   POST   {"name": "dead-air"}  start <scenario_dir>/dead-air.yml
+	//   DELETE                       stop
+	mux.HandleFunc("/api/mock/scenario", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, engine.ScenarioStatus())
+		case http.MethodPost:
+			if !engine.CheckAdmin(r) {
+				writeAPIError(w, http.StatusUnauthorized, "unauthorized")
+				return
+			}
+			var body struct {
+				Name string `json:"name"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				writeAPIError(w, http.StatusBadRequest, "bad json")
+				return
+			}
+			st, err := engine.StartScenario(body.Name)
+			if err != nil {
+				writeAPIError(w, http.StatusConflict, err.Error())
+				return
+			}
+			writeJSON(w, http.StatusOK, st)
+		case http.MethodDelete:
+			if !engine.CheckAdmin(r) {
+				writeAPIError(w, http.StatusUnauthorized, "unauthorized")
+				return
+			}
+			writeJSON(w, http.StatusOK, engine.StopScenario())
+		default:
+			writeAPIError(w, http.StatusMethodNotAllowed, "GET/POST/DELETE required")
+		}
+	})
+
+	// v0.3.70: runtime event journal (read-only).
+	// Query params: ?n=100 (max 1000), ?type=dsp. (prefix) or exact,
+	// ?severity=warn (minimum), ?since=<id|RFC3339>
+	mux.HandleFunc("/api/events", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeAPIError(w, http.StatusMethodNotAllowed, "GET required")
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"ok":     true,
+			"events": engine.ReadJournal(app.ParseJournalQuery(r.URL.Query())),
+		})
+	})
+
 	mux.HandleFunc("/api/reconnect", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
 			writeAPIError(w, http.StatusMethodNotAllowed, "POST required")
 			return
 		}
-		engine.Reconnect()
-		w.WriteHeader(http.StatusNoContent)
+		who, err := engine.ResolveIdentity(r)
+		if err != nil {
+			writeAPIError(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		if !app.RoleAtLeast(who.Role, app.RoleOperator) {
+			writeAPIError(w, http.StatusForbidden, "operator role required")
+			return
+		}
+		// v0.3.73: real reconnect; the result lists each step's outcome.
+		writeJSON(w, http.StatusOK, engine.Reconnect())
 	})
 
 	// Update check (GitHub latest release). No admin PIN required; safe read-only.
@@ -608,7 +1104,7 @@ func main() {
 			return
 		}
 		// Best-effort: if we fail to create the flag, return a helpful error.
-		if err := app.RequestEngineRestart("manual restart requested from UI"); err != nil {
+		if err := engine.RequestRestart("manual restart requested from UI"); err != nil {
 			writeAPIError(w, http.StatusInternalServerError, err.Error())
 			return
 		}
@@ -654,21 +1150,51 @@ func main() {
 		_ = json.NewEncoder(w).Encode(resp)
 	})
 
+	// Stream sessions (v0.3.59).
+	//
+	// POST   /api/session  {"token":"..."} or X-Admin-PIN header -> sets an HttpOnly
+	//                      session cookie that binds /ws and /api/stream to a role.
+	// GET    /api/session  -> the caller's resolved role (cookie, token, PIN or anonymous)
+	// DELETE /api/session  -> logout
+	mux.HandleFunc("/api/session", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			id, err := engine.ResolveIdentity(r)
+			if err != nil {
+				writeAPIError(w, http.StatusUnauthorized, err.Error())
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]any{"ok": true, "identity": id})
+		case http.MethodPost:
+			var body struct {
+				Token string `json:"token"`
+			}
+			// Body is optional when logging in with the admin PIN header.
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			sess, err := engine.CreateSession(r, body.Token)
+			if err != nil {
+				writeAPIError(w, http.StatusUnauthorized, err.Error())
+				return
+			}
+			http.SetCookie(w, engine.SessionCookie(sess))
+			writeJSON(w, http.StatusOK, map[string]any{"ok": true, "session": sess})
+		case http.MethodDelete:
+			if sid := engine.SessionIDFromRequest(r); sid != "" {
+				engine.EndSession(sid)
+			}
+			http.SetCookie(w, engine.ClearSessionCookie())
+			writeJSON(w, http.StatusOK, map[string]any{"ok": true})
+		default:
+			writeAPIError(w, http.StatusMethodNotAllowed, "GET, POST or DELETE required")
+		}
+	})
+
 	// WebSocket stream
 	mux.HandleFunc("/ws", engine.HandleWS)
 
-	addr := cfg.UI.HTTPListen
-	srv := &http.Server{
-		Addr:              addr,
-		Handler:           mux,
-		ReadHeaderTimeout: 5 * time.Second,
-	}
-
-	log.Printf("stub-engine %s listening on %s", engine.Version(), addr)
-	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
-	}
+	// Server-Sent Events stream (read-only consumers: on-air sign, wallboard, curl).
+	// Same snapshot/delta/event model as /ws; ?topics= filter; Last-Event-ID resume.
+	mux.HandleFunc("/api/stream", engine.HandleSSE)
 }
 
 // requireAdminPin is a tiny helper used by a couple of admin-only routes.
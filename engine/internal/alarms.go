@@ -0,0 +1,496 @@
+package app
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Audio alarms: dead-air / silence detection (v0.3.62)
+//
+// Nothing used to watch the program bus; a silent STUB_PGM_L/R went unnoticed
+// until a listener called. The engine now evaluates alarms on the processed
+// meters (after ballistics + calibration, see meters.go), once per meter step:
+//
+//	group    meters
+//	program  STUB_PGM_L, STUB_PGM_R (411/412)
+//	remote   STUB_RSR_L, STUB_RSR_R (462/463)
+//
+// A group is silent when its LOUDEST channel stays below threshold_db for
+// duration_s. It recovers only when the loudest channel stays at or above
+// threshold_db + hysteresis_db for recovery_s, so a quiet fade does not make
+// the alarm flap.
+//
+// Transitions are:
+//   - appended to state/alarms_timeline.jsonl (bounded, like the DSP timeline)
+//   - published as "alarm.silence" events (WS events topic + /api/stream)
+//   - visible in StudioStatus.alarms while active
+//
+// Alarms are visibility-only: they never touch the DSP.
+//
+// Over-level / clipping (v0.3.63)
+//
+//	group    meters
+//	program  STUB_PGM_L, STUB_PGM_R (411/412)
+//	speaker  STUB_SPK_L, STUB_SPK_R (460/461)
+//
+// A group is "over" when its loudest channel sits at or above ceiling_db for
+// hold_ms, measured on the calibrated instantaneous value rather than the
+// ballistics level (a VU or PPM meter would smooth a real over away); the
+// episode ends once it stays below ceiling_db - hysteresis_db for
+// recovery_ms. Each finished episode (start, duration, peak dBFS) is appended
+// to state/over_episodes.jsonl and counted per hour by OverReport(), so
+// engineers can review gain staging after a show. Overs are too frequent for
+// the alarms timeline; they are published as "alarm.over" events instead.
+// ---------------------------------------------------------------------------
+
+const (
+	AlarmSilence = "silence"
+	AlarmOver    = "over"
+
+	alarmTimelineMaxLines = 500
+	overEpisodesMaxLines  = 5000
+)
+
+var (
+	alarmMetersProgram = []string{"STUB_PGM_L", "STUB_PGM_R"}
+	alarmMetersRemote  = []string{"STUB_RSR_L", "STUB_RSR_R"}
+	alarmMetersSpeaker = []string{"STUB_SPK_L", "STUB_SPK_R"}
+)
+
+// alarmWatches lists every alarm the engine evaluates and the meters it watches.
+var alarmWatches = []struct {
+	Kind   string
+	Group  string
+	Meters []string
+}{
+	{AlarmSilence, "program", alarmMetersProgram},
+	{AlarmSilence, "remote", alarmMetersRemote},
+	{AlarmOver, "program", alarmMetersProgram},
+	{AlarmOver, "speaker", alarmMetersSpeaker},
+}
+
+// alarmState tracks one alarm (kind + group). Guarded by e.mu.
+type alarmState struct {
+	kind   string
+	group  string
+	active bool
+	since  time.Time // when the alarm went active
+
+	// pendingSince / recoverSince start when the level first crosses the
+	// trip / recovery threshold; zero while the condition is not met.
+	pendingSince time.Time
+	recoverSince time.Time
+
+	// onset is when the condition began (pendingSince at trip time);
+	// peakDB is the loudest level since then (over-level episodes).
+	onset   time.Time
+	levelDB float64
+	peakDB  float64
+}
+
+// AlarmStatus is the public view of an active alarm.
+type AlarmStatus struct {
+	Kind    string  `json:"kind"`
+	Group   string  `json:"group"`
+	Since   string  `json:"since"`
+	LevelDB float64 `json:"levelDb"`
+}
+
+// alarmTransition is one start/clear, collected under e.mu and recorded after.
+type alarmTransition struct {
+	Time     string  `json:"time"`
+	Kind     string  `json:"kind"`
+	Group    string  `json:"group"`
+	State    string  `json:"state"` // active|clear
+	LevelDB  float64 `json:"levelDb"`
+	Duration float64 `json:"durationS,omitempty"` // on clear: how long the alarm was active
+	Reason   string  `json:"reason,omitempty"`
+
+	// Episode is set on over-level clears.
+	Episode *OverEpisode `json:"-"`
+}
+
+// OverEpisode is one finished over-level episode (state/over_episodes.jsonl).
+type OverEpisode struct {
+	Start     string  `json:"start"`
+	End       string  `json:"end"`
+	Group     string  `json:"group"`
+	DurationS float64 `json:"durationS"`
+	PeakDB    float64 `json:"peakDb"`
+	CeilingDB float64 `json:"ceilingDb"`
+}
+
+func alarmKey(kind, group string) string { return kind + ":" + group }
+
+// alarmGroupLevelLocked returns the loudest level (dBFS) in a group and
+// whether any of its meters are configured. Silence uses the ballistics
+// level; overs use the calibrated instantaneous value (raw). Caller MUST
+// hold e.mu.
+func (e *Engine) alarmGroupLevelLocked(names []string, raw bool) (float64, bool) {
+	level, ok := math.Inf(-1), false
+	for _, n := range names {
+		ch, found := e.meters[rcNameToID[n]]
+		if !found {
+			continue
+		}
+		ok = true
+		if raw {
+			level = math.Max(level, ch.rawDB)
+		} else {
+			level = math.Max(level, ch.db)
+		}
+	}
+	return level, ok
+}
+
+// evaluateAlarmsLocked advances every alarm by one meter step and returns the
+// transitions. Caller MUST hold e.mu; record the result after unlocking.
+func (e *Engine) evaluateAlarmsLocked(cfg Config, now time.Time) []alarmTransition {
+	var out []alarmTransition
+	sc, oc := cfg.Alarms.Silence, cfg.Alarms.Over
+	for _, w := range alarmWatches {
+		level, ok := e.alarmGroupLevelLocked(w.Meters, w.Kind == AlarmOver)
+		if !ok {
+			continue
+		}
+		st := e.alarmLocked(w.Kind, w.Group)
+		st.levelDB = level
+		if st.active || !st.pendingSince.IsZero() {
+			st.peakDB = math.Max(st.peakDB, level)
+		} else {
+			st.peakDB = level
+		}
+
+		var disabled, tripping, recovered bool
+		var hold, recovery time.Duration
+		switch w.Kind {
+		case AlarmSilence:
+			disabled = sc.Disabled
+			tripping = level < sc.threshold()
+			recovered = level >= sc.threshold()+sc.HysteresisDB
+			hold, recovery = seconds(sc.DurationS), seconds(sc.RecoveryS)
+		case AlarmOver:
+			disabled = oc.Disabled
+			tripping = level >= oc.ceiling()
+			recovered = level < oc.ceiling()-oc.HysteresisDB
+			hold, recovery = millis(oc.HoldMs), millis(oc.RecoveryMs)
+		}
+		if disabled {
+			if st.active {
+				out = append(out, st.clear(now, "disabled"))
+			}
+			st.pendingSince, st.recoverSince = time.Time{}, time.Time{}
+			continue
+		}
+		tr, changed := st.step(now, tripping, recovered, hold, recovery)
+		if !changed {
+			continue
+		}
+		if w.Kind == AlarmOver && tr.State == "clear" {
+			tr.Episode = st.episode(now, oc.ceiling())
+		}
+		out = append(out, tr)
+	}
+	return out
+}
+
+// episode summarizes the over-level episode that just cleared. It ends when
+// the level first fell back below the recovery threshold, not when the
+// recovery timer expired.
+func (st *alarmState) episode(now time.Time, ceiling float64) *OverEpisode {
+	end := now
+	if !st.recoverSince.IsZero() {
+		end = st.recoverSince
+	}
+	return &OverEpisode{
+		Start:     st.onset.UTC().Format(time.RFC3339Nano),
+		End:       end.UTC().Format(time.RFC3339Nano),
+		Group:     st.group,
+		DurationS: math.Round(end.Sub(st.onset).Seconds()*1000) / 1000,
+		PeakDB:    math.Round(st.peakDB*10) / 10,
+		CeilingDB: ceiling,
+	}
+}
+
+func (e *Engine) alarmLocked(kind, group string) *alarmState {
+	k := alarmKey(kind, group)
+	st, ok := e.alarms[k]
+	if !ok {
+		st = &alarmState{kind: kind, group: group}
+		e.alarms[k] = st
+	}
+	return st
+}
+
+// step applies the trip/recover timers. tripping is the alarm condition;
+// recovered is the (hysteresis-adjusted) all-clear condition.
+func (st *alarmState) step(now time.Time, tripping, recovered bool, hold, recovery time.Duration) (alarmTransition, bool) {
+	if !st.active {
+		st.recoverSince = time.Time{}
+		if !tripping {
+			st.pendingSince = time.Time{}
+			return alarmTransition{}, false
+		}
+		if st.pendingSince.IsZero() {
+			st.pendingSince = now
+		}
+		if now.Sub(st.pendingSince) < hold {
+			return alarmTransition{}, false
+		}
+		st.active = true
+		st.since = now
+		st.onset = st.pendingSince
+		st.pendingSince = time.Time{}
+		return st.transition(now, "active"), true
+	}
+	if !recovered {
+		st.recoverSince = time.Time{}
+		return alarmTransition{}, false
+	}
+	if st.recoverSince.IsZero() {
+		st.recoverSince = now
+	}
+	if now.Sub(st.recoverSince) < recovery {
+		return alarmTransition{}, false
+	}
+	return st.clear(now, ""), true
+}
+
+func (st *alarmState) clear(now time.Time, reason string) alarmTransition {
+	tr := st.transition(now, "clear")
+	tr.Duration = math.Round(now.Sub(st.since).Seconds()*10) / 10
+	tr.Reason = reason
+	st.active = false
+	st.since = time.Time{}
+	return tr
+}
+
+func (st *alarmState) transition(now time.Time, state string) alarmTransition {
+	return alarmTransition{
+		Time:    now.UTC().Format(time.RFC3339),
+		Kind:    st.kind,
+		Group:   st.group,
+		State:   state,
+		LevelDB: math.Round(st.levelDB*10) / 10,
+	}
+}
+
+func seconds(s float64) time.Duration { return time.Duration(s * float64(time.Second)) }
+
+func millis(ms float64) time.Duration { return time.Duration(ms * float64(time.Millisecond)) }
+
+// activeAlarmsLocked lists active alarms sorted by kind/group. Caller MUST hold e.mu.
+func (e *Engine) activeAlarmsLocked() []AlarmStatus {
+	out := []AlarmStatus{}
+	for _, st := range e.alarms {
+		if !st.active {
+			continue
+		}
+		out = append(out, AlarmStatus{
+			Kind:    st.kind,
+			Group:   st.group,
+			Since:   st.since.UTC().Format(time.RFC3339),
+			LevelDB: math.Round(st.levelDB*10) / 10,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return alarmKey(out[i].Kind, out[i].Group) < alarmKey(out[j].Kind, out[j].Group)
+	})
+	return out
+}
+
+// ActiveAlarms returns the currently active alarms.
+func (e *Engine) ActiveAlarms() []AlarmStatus {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.activeAlarmsLocked()
+}
+
+// recordAlarmTransitions persists and publishes transitions.
+// Callers MUST NOT hold engine locks (see PublishEvent).
+func (e *Engine) recordAlarmTransitions(trs []alarmTransition) {
+	for _, tr := range trs {
+		details := map[string]any{
+			"group":   tr.Group,
+			"state":   tr.State,
+			"levelDb": tr.LevelDB,
+		}
+		if tr.State == "clear" {
+			details["durationS"] = tr.Duration
+		}
+		if tr.Kind == AlarmOver {
+			if tr.Episode != nil {
+				e.appendOverEpisode(*tr.Episode)
+				details["durationS"] = tr.Episode.DurationS
+				details["peakDb"] = tr.Episode.PeakDB
+			}
+		} else {
+			e.appendAlarmTimeline(tr)
+		}
+		if tr.Reason != "" {
+			details["reason"] = tr.Reason
+		}
+		sev := SeverityInfo
+		if tr.State != "clear" {
+			sev = SeverityWarn
+		}
+		e.RecordEvent("alarm."+tr.Kind, sev, details)
+	}
+}
+
+func (e *Engine) alarmTimelinePath() string {
+	if strings.TrimSpace(e.stateDir) == "" {
+		return ""
+	}
+	return filepath.Join(e.stateDir, "alarms_timeline.jsonl")
+}
+
+// appendAlarmTimeline appends one transition (best-effort, bounded).
+func (e *Engine) appendAlarmTimeline(tr alarmTransition) {
+	path := e.alarmTimelinePath()
+	if path == "" {
+		return
+	}
+	_ = os.MkdirAll(filepath.Dir(path), 0755)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err == nil {
+		enc, _ := json.Marshal(tr)
+		_, _ = f.Write(append(enc, '\n'))
+		_ = f.Close()
+	}
+	e.boundDSPTimeline(path, alarmTimelineMaxLines)
+}
+
+// ReadAlarmTimeline returns the last n alarm transitions (oldest first).
+func (e *Engine) ReadAlarmTimeline(n int) []alarmTransition {
+	if n <= 0 {
+		n = 50
+	}
+	path := e.alarmTimelinePath()
+	if path == "" {
+		return nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	raw := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(raw) > n {
+		raw = raw[len(raw)-n:]
+	}
+	out := make([]alarmTransition, 0, len(raw))
+	for _, line := range raw {
+		var tr alarmTransition
+		if json.Unmarshal([]byte(strings.TrimSpace(line)), &tr) == nil {
+			out = append(out, tr)
+		}
+	}
+	return out
+}
+
+func (e *Engine) overEpisodesPath() string {
+	if strings.TrimSpace(e.stateDir) == "" {
+		return ""
+	}
+	return filepath.Join(e.stateDir, "over_episodes.jsonl")
+}
+
+// appendOverEpisode appends one finished episode (best-effort, bounded).
+func (e *Engine) appendOverEpisode(ep OverEpisode) {
+	path := e.overEpisodesPath()
+	if path == "" {
+		return
+	}
+	_ = os.MkdirAll(filepath.Dir(path), 0755)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err == nil {
+		enc, _ := json.Marshal(ep)
+		_, _ = f.Write(append(enc, '\n'))
+		_ = f.Close()
+	}
+	e.boundDSPTimeline(path, overEpisodesMaxLines)
+}
+
+// OverHour is the over-level count for one clock hour (UTC).
+type OverHour struct {
+	Hour    string         `json:"hour"`
+	Total   int            `json:"total"`
+	ByGroup map[string]int `json:"byGroup"`
+	// PeakDB is the loudest episode peak in the hour.
+	PeakDB float64 `json:"peakDb"`
+	// OverS is the summed episode duration in the hour (seconds).
+	OverS float64 `json:"overS"`
+}
+
+// OverReport is the gain-staging review served by /api/alarms/overs.
+type OverReport struct {
+	Ok        bool          `json:"ok"`
+	From      string        `json:"from"`
+	To        string        `json:"to"`
+	CeilingDB float64       `json:"ceilingDb"`
+	Total     int           `json:"total"`
+	Hours     []OverHour    `json:"hours"`
+	Episodes  []OverEpisode `json:"episodes"`
+}
+
+// OverReport summarizes over-level episodes that started in the last `hours`
+// hours: counts per clock hour (oldest first, empty hours included) and the
+// episodes themselves (newest first, at most maxEpisodes).
+func (e *Engine) OverReport(hours, maxEpisodes int) OverReport {
+	now := time.Now().UTC()
+	from := now.Truncate(time.Hour).Add(-time.Duration(hours-1) * time.Hour)
+	rep := OverReport{
+		Ok:        true,
+		From:      from.Format(time.RFC3339),
+		To:        now.Format(time.RFC3339),
+		CeilingDB: e.GetConfigCopy().Alarms.Over.ceiling(),
+		Hours:     make([]OverHour, hours),
+		Episodes:  []OverEpisode{},
+	}
+	for i := range rep.Hours {
+		rep.Hours[i] = OverHour{
+			Hour:    from.Add(time.Duration(i) * time.Hour).Format(time.RFC3339),
+			ByGroup: map[string]int{},
+			PeakDB:  defaultMeterFloorDB,
+		}
+	}
+
+	var eps []OverEpisode
+	if path := e.overEpisodesPath(); path != "" {
+		if b, err := os.ReadFile(path); err == nil {
+			for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+				var ep OverEpisode
+				if json.Unmarshal([]byte(strings.TrimSpace(line)), &ep) == nil {
+					eps = append(eps, ep)
+				}
+			}
+		}
+	}
+	for i := len(eps) - 1; i >= 0; i-- {
+		ep := eps[i]
+		t, err := time.Parse(time.RFC3339Nano, ep.Start)
+		if err != nil || t.Before(from) {
+			continue
+		}
+		idx := int(t.Sub(from) / time.Hour)
+		if idx >= len(rep.Hours) {
+			continue
+		}
+		h := &rep.Hours[idx]
+		h.Total++
+		h.ByGroup[ep.Group]++
+		h.PeakDB = math.Max(h.PeakDB, ep.PeakDB)
+		h.OverS = math.Round((h.OverS+ep.DurationS)*1000) / 1000
+		rep.Total++
+		if len(rep.Episodes) < maxEpisodes {
+			rep.Episodes = append(rep.Episodes, ep)
+		}
+	}
+	return rep
+}
@@ -0,0 +1,266 @@
+package app
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Stream origin policy + role-bound connections (v0.3.59)
+//
+// Before this release any page on the LAN could open /ws and watch the studio
+// (CheckOrigin always returned true). Once commands flow over the socket that
+// would also mean controlling it, so:
+//
+//   - Origin: browsers must connect from the engine's own origin or one listed
+//     in ws.allowed_origins ("*" restores the old allow-all behavior).
+//     Requests without an Origin header (curl, scripts) are not browser
+//     cross-site requests and are allowed; they still go through auth.
+//   - Auth: every connection is bound to a role at handshake time:
+//       viewer    read-only
+//       operator  may send control commands
+//       admin     operator + Engineering actions
+//     Credentials, in order: session cookie, bearer token (Authorization
+//     header or ?token= since browsers cannot set WebSocket headers), admin
+//     PIN header. Without credentials the caller gets auth.anonymous_role.
+//
+// Sessions are in-memory only; an engine restart logs everyone out, which is
+// the conservative outcome.
+// ---------------------------------------------------------------------------
+
+const (
+	RoleNone     = "none"
+	RoleViewer   = "viewer"
+	RoleOperator = "operator"
+	RoleAdmin    = "admin"
+
+	sessionCookieName = "studiob_session"
+	sessionTTL        = 12 * time.Hour
+)
+
+// roleRank orders roles; 0 means unknown/none.
+func roleRank(role string) int {
+	switch role {
+	case RoleViewer:
+		return 1
+	case RoleOperator:
+		return 2
+	case RoleAdmin:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// RoleAtLeast reports whether role grants at least the privileges of min.
+func RoleAtLeast(role, min string) bool {
+	return roleRank(role) > 0 && roleRank(role) >= roleRank(min)
+}
+
+// Session is a logged-in caller (cookie-backed).
+type Session struct {
+	ID        string `json:"-"`
+	Name      string `json:"name"`
+	Role      string `json:"role"`
+	CreatedAt string `json:"createdAt"`
+	ExpiresAt string `json:"expiresAt"`
+
+	expires time.Time
+}
+
+// Identity is the resolved caller for one request or connection.
+type Identity struct {
+	Role string `json:"role"`
+	Name string `json:"name,omitempty"`
+	// Via is how the role was established: session|token|pin|anonymous.
+	Via string `json:"via"`
+}
+
+// lookupToken finds a configured static token (constant-time compare).
+func lookupToken(cfg Config, tok string) (AuthToken, bool) {
+	tok = strings.TrimSpace(tok)
+	if tok == "" {
+		return AuthToken{}, false
+	}
+	for _, t := range cfg.Auth.Tokens {
+		if strings.TrimSpace(t.Token) == "" {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(tok), []byte(t.Token)) == 1 {
+			return t, true
+		}
+	}
+	return AuthToken{}, false
+}
+
+func bearerToken(r *http.Request) string {
+	h := strings.TrimSpace(r.Header.Get("Authorization"))
+	if len(h) > 7 && strings.EqualFold(h[:7], "bearer ") {
+		return strings.TrimSpace(h[7:])
+	}
+	return strings.TrimSpace(r.URL.Query().Get("token"))
+}
+
+// ResolveIdentity binds a request to a role.
+//
+// A stale or unknown session cookie is ignored (browsers keep cookies across
+// engine restarts). An explicitly presented token or PIN that does not match
+// is an error: the caller asked for privileges we cannot grant.
+func (e *Engine) ResolveIdentity(r *http.Request) (Identity, error) {
+	cfg := e.GetConfigCopy()
+
+	if sid := e.SessionIDFromRequest(r); sid != "" {
+		if s, ok := e.lookupSession(sid); ok {
+			return Identity{Role: s.Role, Name: s.Name, Via: "session"}, nil
+		}
+	}
+	if tok := bearerToken(r); tok != "" {
+		t, ok := lookupToken(cfg, tok)
+		if !ok {
+			return Identity{}, fmt.Errorf("invalid token")
+		}
+		return Identity{Role: t.Role, Name: t.Name, Via: "token"}, nil
+	}
+	if pin := strings.TrimSpace(r.Header.Get("X-Admin-PIN")); pin != "" {
+		if !e.CheckAdmin(r) {
+			return Identity{}, fmt.Errorf("invalid admin PIN")
+		}
+		return Identity{Role: RoleAdmin, Name: "admin", Via: "pin"}, nil
+	}
+	if cfg.Auth.AnonymousRole == RoleNone || roleRank(cfg.Auth.AnonymousRole) == 0 {
+		return Identity{}, fmt.Errorf("authentication required")
+	}
+	return Identity{Role: cfg.Auth.AnonymousRole, Via: "anonymous"}, nil
+}
+
+// CreateSession logs a caller in with a static token or the admin PIN and
+// returns the session (the caller sets the cookie).
+func (e *Engine) CreateSession(r *http.Request, token string) (Session, error) {
+	cfg := e.GetConfigCopy()
+	var name, role string
+	if t, ok := lookupToken(cfg, token); ok {
+		name, role = t.Name, t.Role
+	} else if strings.TrimSpace(token) != "" {
+		return Session{}, fmt.Errorf("invalid token")
+	} else if strings.TrimSpace(r.Header.Get("X-Admin-PIN")) != "" && e.CheckAdmin(r) {
+		name, role = "admin", RoleAdmin
+	} else {
+		return Session{}, fmt.Errorf("token or admin PIN required")
+	}
+
+	var raw [24]byte
+	if _, err := rand.Read(raw[:]); err != nil {
+		return Session{}, err
+	}
+	now := time.Now().UTC()
+	s := Session{
+		ID:        hex.EncodeToString(raw[:]),
+		Name:      name,
+		Role:      role,
+		CreatedAt: now.Format(time.RFC3339),
+		ExpiresAt: now.Add(sessionTTL).Format(time.RFC3339),
+		expires:   now.Add(sessionTTL),
+	}
+	e.sessionsMu.Lock()
+	e.sessions[s.ID] = s
+	// Opportunistic cleanup keeps the map bounded without a janitor goroutine.
+	for id, old := range e.sessions {
+		if now.After(old.expires) {
+			delete(e.sessions, id)
+		}
+	}
+	e.sessionsMu.Unlock()
+	return s, nil
+}
+
+// EndSession removes a session (logout). Unknown IDs are ignored.
+func (e *Engine) EndSession(id string) {
+	e.sessionsMu.Lock()
+	delete(e.sessions, id)
+	e.sessionsMu.Unlock()
+}
+
+func (e *Engine) lookupSession(id string) (Session, bool) {
+	e.sessionsMu.Lock()
+	defer e.sessionsMu.Unlock()
+	s, ok := e.sessions[id]
+	if !ok {
+		return Session{}, false
+	}
+	if time.Now().After(s.expires) {
+		delete(e.sessions, id)
+		return Session{}, false
+	}
+	return s, true
+}
+
+// sessionCookieScope returns the session cookie name and path for this
+// engine. Each studio keeps its own session store (v0.3.72), so an
+// additional studio uses its own cookie scoped to /studios/<name>/ and
+// never sees, or overwrites, the default studio's cookie.
+func (e *Engine) sessionCookieScope() (name, path string) {
+	if e.studio == "" {
+		return sessionCookieName, "/"
+	}
+	return sessionCookieName + "_" + e.studio, "/studios/" + e.studio + "/"
+}
+
+// SessionCookie builds the Set-Cookie value for a session.
+// HttpOnly keeps it away from page scripts; SameSite=Strict keeps other
+// sites from riding it.
+func (e *Engine) SessionCookie(s Session) *http.Cookie {
+	name, path := e.sessionCookieScope()
+	return &http.Cookie{
+		Name:     name,
+		Value:    s.ID,
+		Path:     path,
+		Expires:  s.expires,
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	}
+}
+
+// ClearSessionCookie builds the Set-Cookie value that removes the session
+// cookie (logout).
+func (e *Engine) ClearSessionCookie() *http.Cookie {
+	name, path := e.sessionCookieScope()
+	return &http.Cookie{Name: name, Value: "", Path: path, MaxAge: -1}
+}
+
+// SessionIDFromRequest returns the caller's session cookie value, if any.
+func (e *Engine) SessionIDFromRequest(r *http.Request) string {
+	name, _ := e.sessionCookieScope()
+	if ck, err := r.Cookie(name); err == nil {
+		return ck.Value
+	}
+	return ""
+}
+
+// checkWSOrigin implements the ws.allowed_origins policy.
+func (e *Engine) checkWSOrigin(r *http.Request) bool {
+	origin := strings.TrimSpace(r.Header.Get("Origin"))
+	if origin == "" {
+		// Not a browser cross-site request (curl, scripts, native clients).
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	if strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	for _, a := range e.GetConfigCopy().WS.AllowedOrigins {
+		a = strings.TrimRight(strings.TrimSpace(a), "/")
+		if a == "*" || strings.EqualFold(a, origin) {
+			return true
+		}
+	}
+	return false
+}
@@ -41,8 +41,30 @@ type Config struct {
 		Host string `yaml:"host"`
 		Port int    `yaml:"port"`
 		Mode string `yaml:"mode"` // "mock" for v1
+		// Health tunes the DSP health monitor / state machine (v0.3.67).
+		Health DSPHealthPolicy `yaml:"health"`
+		// Backups are the other Cores of a redundant pair/group, in order of
+		// preference after host:port (v0.3.71, see dsp_cores.go).
+		Backups []DSPEndpoint `yaml:"backups"`
+		// Arm bounds how long LIVE writes stay armed (v0.3.74, see dsp_arming.go).
+		Arm DSPArmPolicy `yaml:"arm"`
+		// Conformance configures the design check (v0.3.81, see dsp_conformance.go).
+		Conformance DSPConformancePolicy `yaml:"conformance"`
 	} `yaml:"dsp"`
 
+	// Mock configures mock-mode scenarios (v0.3.65, see mock_scenario.go).
+	Mock struct {
+		// Scenario is started at boot in mock mode (absolute, or relative to
+		// the config directory).
+		Scenario string `yaml:"scenario"`
+		// ScenarioDir is the library the API may start scenarios from
+		// (default: <base>/scenarios next to state/).
+		ScenarioDir string `yaml:"scenario_dir"`
+		// Model picks how mock meters are generated (v0.3.66): "mixer"
+		// (default; meters follow faders and mutes) or "random" (random walk).
+		Model string `yaml:"model"`
+	} `yaml:"mock"`
+
 	UI struct {
 		HTTPListen    string `yaml:"http_listen"`
 		PublicBaseURL string `yaml:"public_base_url"`
@@ -55,8 +77,57 @@ type Config struct {
 	Meters struct {
 		PublishHz int     `yaml:"publish_hz"`
 		Deadband  float64 `yaml:"deadband"`
+		// Ballistics is the default meter processing for every meter (v0.3.60).
+		Ballistics MeterBallistics `yaml:"ballistics"`
+		// PerMeter overrides Ballistics per meter, keyed by RC name or id
+		// (e.g. STUB_PGM_L or "411"). Zero fields inherit from Ballistics.
+		PerMeter map[string]MeterBallistics `yaml:"per_meter"`
+		// Calibration maps each meter (by RC name or id) from source units to
+		// dBFS (v0.3.61). Meters not listed use linear / 0 dB offset / -60 dB floor.
+		Calibration map[string]MeterCalibration `yaml:"calibration"`
+		// History records min/max/avg trends per meter (v0.3.64).
+		History MeterHistoryConfig `yaml:"history"`
 	} `yaml:"meters"`
 
+	// Alarms configures audio alarms evaluated on the processed meters (v0.3.62).
+	Alarms struct {
+		Silence SilenceAlarm `yaml:"silence"`
+		// Over is over-level / clipping detection (v0.3.63).
+		Over OverAlarm `yaml:"over"`
+	} `yaml:"alarms"`
+
+	// WS controls the /ws WebSocket stream.
+	WS struct {
+		// DisableCompression turns off permessage-deflate negotiation.
+		// Compression is on by default; it only applies to JSON frames
+		// (binary meter frames are already compact and are sent uncompressed).
+		DisableCompression bool `yaml:"disable_compression"`
+		// AllowedOrigins lists browser origins (scheme://host[:port]) that may
+		// open /ws in addition to the engine's own origin. "*" allows any origin.
+		AllowedOrigins []string `yaml:"allowed_origins"`
+	} `yaml:"ws"`
+
+	// Auth binds stream connections (and, later, API callers) to a role.
+	// Roles, lowest to highest: viewer (read-only), operator (control), admin.
+	Auth struct {
+		// AnonymousRole is granted to callers without credentials:
+		// "viewer" (default), "operator", or "none" (reject).
+		AnonymousRole string `yaml:"anonymous_role"`
+		// Tokens are static bearer tokens for kiosks, signs and scripts.
+		Tokens []AuthToken `yaml:"tokens"`
+	} `yaml:"auth"`
+
+	// ShowLock configures on-air write freezes (v0.3.75, see show_lock.go).
+	ShowLock struct {
+		// Groups name sets of controls (RC ids or STUB_* names) a lock can
+		// freeze by group. Default: speaker, mics, faders.
+		Groups map[string][]string `yaml:"groups"`
+	} `yaml:"show_lock"`
+
+	// Studios are additional rooms hosted by this engine (v0.3.72, see
+	// studios.go). Only read from the default studio's config.
+	Studios []StudioRef `yaml:"studios"`
+
 	Updates struct {
 		Mode        string `yaml:"mode"`          // "zip" (default) or "git"
 		GitHubRepo  string `yaml:"github_repo"`   // e.g. "WLCB/StudioB-UI"
@@ -67,11 +138,145 @@ type Config struct {
 
 	RCAllowlist []int `yaml:"rc_allowlist"`
 
+	// ControlPolicies are per-control write rules keyed by RC name or id
+	// (v0.3.76, see control_policy.go). Controls not listed accept any finite
+	// value from any caller, as before.
+	ControlPolicies map[string]ControlPolicy `yaml:"control_policies"`
+
 	// Meta is not loaded from YAML; it is populated by LoadConfig() for debugging.
 	Meta ConfigMeta `yaml:"-" json:"-"`
 }
 
+// DSPHealthPolicy configures the DSP health state machine (see dsp_health.go).
+//
+// Each probe is one bounded TCP connect every PollMs (default 2000) with
+// TimeoutMs (default 1200). DegradeAfter consecutive failures (default 1)
+// mark the link DEGRADED and DisconnectAfter (default 3) DISCONNECTED. After a
+// failure the link must pass RecoverAfter consecutive probes (default 2)
+// before it is OK again; until then it stays DEGRADED.
+//
+// LatencyWindow (default 50) is the number of latency samples kept per kind;
+// a connect or command p95 at or above LatencyDegradeMs (default 250, -1 disables)
+// degrades an otherwise healthy link (v0.3.68, see dsp_latency.go).
+type DSPHealthPolicy struct {
+	PollMs           int `yaml:"poll_ms" json:"pollMs"`
+	TimeoutMs        int `yaml:"timeout_ms" json:"timeoutMs"`
+	DegradeAfter     int `yaml:"degrade_after" json:"degradeAfter"`
+	DisconnectAfter  int `yaml:"disconnect_after" json:"disconnectAfter"`
+	RecoverAfter     int `yaml:"recover_after" json:"recoverAfter"`
+	LatencyWindow    int `yaml:"latency_window" json:"latencyWindow"`
+	LatencyDegradeMs int `yaml:"latency_degrade_ms" json:"latencyDegradeMs"`
+}
+
+// DSPArmPolicy bounds POST /api/dsp/arm (see dsp_arming.go). An arm request
+// without a TTL gets DefaultTTLSec (default 900); longer than MaxTTLSec
+// (default 14400) is refused.
+type DSPArmPolicy struct {
+	DefaultTTLSec int `yaml:"default_ttl_sec" json:"defaultTtlSec"`
+	MaxTTLSec     int `yaml:"max_ttl_sec" json:"maxTtlSec"`
+}
+
+// MeterBallistics configures the server-side meter stage (see meters.go).
+//
+// Mode picks the integration: "peak" (default; instant attack, release_ms
+// fall), "vu" (~300 ms symmetric integration) or "ppm" (fast attack, slow
+// ~24 dB / 2.8 s fall). AttackMs/ReleaseMs, when set, override the mode's
+// time constants.
+type MeterBallistics struct {
+	Mode       string  `yaml:"mode"`
+	AttackMs   float64 `yaml:"attack_ms"`
+	ReleaseMs  float64 `yaml:"release_ms"`
+	PeakHoldMs float64 `yaml:"peak_hold_ms"`
+}
+
+// MeterCalibration converts a meter's source value to dBFS.
+//
+// Units:
+//   - linear:   0..1 amplitude (default); dBFS = 20*log10(v) + offset_db
+//   - db:       the source already reports dB (Q-SYS meter blocks); dBFS = v + offset_db
+//   - position: 0..1 meter position spanning floor_db..0 dB; dBFS = floor_db*(1-v) + offset_db
+//
+// FloorDB (default -60) is the lowest reported dBFS; quieter values clamp to it.
+type MeterCalibration struct {
+	Units    string  `yaml:"units"`
+	OffsetDB float64 `yaml:"offset_db"`
+	FloorDB  float64 `yaml:"floor_db"`
+}
+
+// MeterHistoryConfig configures the meter trend recorder (see meter_history.go).
+//
+// Meters lists RC names or ids to record (default: every meter). Keep1s /
+// Keep1m / Keep1h are the number of buckets retained per meter at each
+// resolution (defaults: 3600 = 1 h, 1440 = 24 h, 720 = 30 days).
+type MeterHistoryConfig struct {
+	Disabled bool     `yaml:"disabled"`
+	Meters   []string `yaml:"meters"`
+	Keep1s   int      `yaml:"keep_1s"`
+	Keep1m   int      `yaml:"keep_1m"`
+	Keep1h   int      `yaml:"keep_1h"`
+}
+
+// SilenceAlarm configures dead-air detection on the program and remote-return
+// meters (see alarms.go). A group trips when its loudest channel stays below
+// ThresholdDB (dBFS, default -50) for DurationS (default 10) and clears once it
+// stays at or above ThresholdDB+HysteresisDB (default 3) for RecoveryS (default 2).
+// ThresholdDB is a pointer so an explicit 0 dBFS is kept; read it with
+// threshold().
+type SilenceAlarm struct {
+	Disabled     bool     `yaml:"disabled"`
+	ThresholdDB  *float64 `yaml:"threshold_db"`
+	DurationS    float64  `yaml:"duration_s"`
+	HysteresisDB float64  `yaml:"hysteresis_db"`
+	RecoveryS    float64  `yaml:"recovery_s"`
+}
+
+// OverAlarm configures over-level detection on the program and speaker meters
+// (see alarms.go). An episode starts when a group's loudest channel sits at or
+// above CeilingDB (dBFS, default -1) for HoldMs (default 250) and ends once it
+// stays below CeilingDB-HysteresisDB (default 1) for RecoveryMs (default 500).
+// CeilingDB is a pointer so a 0 dBFS ceiling can be configured; read it with
+// ceiling().
+type OverAlarm struct {
+	Disabled     bool     `yaml:"disabled"`
+	CeilingDB    *float64 `yaml:"ceiling_db"`
+	HoldMs       float64  `yaml:"hold_ms"`
+	HysteresisDB float64  `yaml:"hysteresis_db"`
+	RecoveryMs   float64  `yaml:"recovery_ms"`
+}
+
+func (a SilenceAlarm) threshold() float64 {
+	if a.ThresholdDB == nil {
+		return -50
+	}
+	return *a.ThresholdDB
+}
+
+func (a OverAlarm) ceiling() float64 {
+	if a.CeilingDB == nil {
+		return -1
+	}
+	return *a.CeilingDB
+}
+
+// AuthToken is one static credential from auth.tokens.
+type AuthToken struct {
+	Name  string `yaml:"name"`
+	Token string `yaml:"token"`
+	Role  string `yaml:"role"`
+}
+
 func LoadConfig(path string) (*Config, error) {
+	return loadConfig(path, true)
+}
+
+// LoadStudioConfig loads an additional studio's config (v0.3.72). The
+// config.json / STUDIOB_* overrides belong to the default studio and are
+// not applied.
+func LoadStudioConfig(path string) (*Config, error) {
+	return loadConfig(path, false)
+}
+
+func loadConfig(path string, overrides bool) (*Config, error) {
 	b, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
@@ -102,15 +307,206 @@ func LoadConfig(path string) (*Config, error) {
 	if cfg.DSP.Mode == "" {
 		cfg.DSP.Mode = "mock"
 	}
+	for i := 0; i < len(cfg.DSP.Backups); i++ {
+		b := &cfg.DSP.Backups[i]
+		b.Host = strings.TrimSpace(b.Host)
+		if b.Host == "" {
+			cfg.Meta.Warnings = append(cfg.Meta.Warnings, fmt.Sprintf("dsp.backups[%d] has no host; ignored", i))
+			cfg.DSP.Backups = append(cfg.DSP.Backups[:i], cfg.DSP.Backups[i+1:]...)
+			i--
+			continue
+		}
+		if b.Port == 0 {
+			b.Port = 1702
+		}
+	}
+
+	hp := &cfg.DSP.Health
+	if hp.PollMs <= 0 {
+		hp.PollMs = 2000
+	}
+	if hp.PollMs < 250 {
+		cfg.Meta.Warnings = append(cfg.Meta.Warnings, fmt.Sprintf("dsp.health.poll_ms %d is too fast; using 250", hp.PollMs))
+		hp.PollMs = 250
+	}
+	if hp.TimeoutMs <= 0 {
+		hp.TimeoutMs = 1200
+	}
+	if hp.DegradeAfter <= 0 {
+		hp.DegradeAfter = 1
+	}
+	if hp.DisconnectAfter <= 0 {
+		hp.DisconnectAfter = 3
+	}
+	if hp.DisconnectAfter < hp.DegradeAfter {
+		cfg.Meta.Warnings = append(cfg.Meta.Warnings, fmt.Sprintf("dsp.health.disconnect_after %d < degrade_after %d; using %d", hp.DisconnectAfter, hp.DegradeAfter, hp.DegradeAfter))
+		hp.DisconnectAfter = hp.DegradeAfter
+	}
+	if hp.RecoverAfter <= 0 {
+		hp.RecoverAfter = 2
+	}
+	if hp.LatencyWindow <= 0 {
+		hp.LatencyWindow = 50
+	}
+	if hp.LatencyDegradeMs == 0 {
+		hp.LatencyDegradeMs = 250
+	}
+	if hp.LatencyDegradeMs < 0 {
+		hp.LatencyDegradeMs = 0
+	}
+	ap := &cfg.DSP.Arm
+	if ap.MaxTTLSec <= 0 {
+		ap.MaxTTLSec = 14400
+	}
+	if ap.DefaultTTLSec <= 0 {
+		ap.DefaultTTLSec = 900
+	}
+	if ap.DefaultTTLSec > ap.MaxTTLSec {
+		cfg.Meta.Warnings = append(cfg.Meta.Warnings, fmt.Sprintf("dsp.arm.default_ttl_sec %d > max_ttl_sec %d; using %d", ap.DefaultTTLSec, ap.MaxTTLSec, ap.MaxTTLSec))
+		ap.DefaultTTLSec = ap.MaxTTLSec
+	}
+	if strings.TrimSpace(cfg.DSP.Conformance.Prefix) == "" {
+		cfg.DSP.Conformance.Prefix = "STUB_"
+	}
 	if cfg.Meters.PublishHz <= 0 {
 		cfg.Meters.PublishHz = 20
 	}
 	if cfg.Meters.Deadband <= 0 {
 		cfg.Meters.Deadband = 0.01
 	}
+	cfg.Meters.Ballistics.Mode = strings.ToLower(strings.TrimSpace(cfg.Meters.Ballistics.Mode))
+	if cfg.Meters.Ballistics.Mode == "" {
+		cfg.Meters.Ballistics.Mode = MeterModePeak
+	}
+	if cfg.Meters.Ballistics.PeakHoldMs <= 0 {
+		cfg.Meters.Ballistics.PeakHoldMs = 1500
+	}
+	if len(cfg.ShowLock.Groups) == 0 {
+		cfg.ShowLock.Groups = defaultShowLockGroups()
+	}
+	groups := map[string][]string{}
+	for g, ctls := range cfg.ShowLock.Groups {
+		g = strings.ToLower(strings.TrimSpace(g))
+		for _, c := range ctls {
+			if _, err := resolveRC(strings.TrimSpace(c)); err != nil {
+				cfg.Meta.Warnings = append(cfg.Meta.Warnings, fmt.Sprintf("show_lock.groups[%s]: unknown control %q ignored", g, c))
+				continue
+			}
+			groups[g] = append(groups[g], strings.TrimSpace(c))
+		}
+	}
+	cfg.ShowLock.Groups = groups
+	for k, cp := range cfg.ControlPolicies {
+		cfg.ControlPolicies[k] = normalizeControlPolicy(k, cp, &cfg.Meta.Warnings)
+	}
+	for k, b := range cfg.Meters.PerMeter {
+		b.Mode = strings.ToLower(strings.TrimSpace(b.Mode))
+		if _, err := resolveRC(k); err != nil {
+			cfg.Meta.Warnings = append(cfg.Meta.Warnings, fmt.Sprintf("meters.per_meter: unknown meter %q ignored", k))
+		}
+		if b.Mode != "" && b.Mode != MeterModePeak && b.Mode != MeterModeVU && b.Mode != MeterModePPM {
+			cfg.Meta.Warnings = append(cfg.Meta.Warnings, fmt.Sprintf("meters.per_meter[%s]: invalid mode %q; using default", k, b.Mode))
+			b.Mode = ""
+		}
+		cfg.Meters.PerMeter[k] = b
+	}
+	for k, c := range cfg.Meters.Calibration {
+		c.Units = strings.ToLower(strings.TrimSpace(c.Units))
+		if _, err := resolveRC(k); err != nil {
+			cfg.Meta.Warnings = append(cfg.Meta.Warnings, fmt.Sprintf("meters.calibration: unknown meter %q ignored", k))
+		}
+		switch c.Units {
+		case "":
+			c.Units = MeterUnitsLinear
+		case MeterUnitsLinear, MeterUnitsDB, MeterUnitsPosition:
+		default:
+			cfg.Meta.Warnings = append(cfg.Meta.Warnings, fmt.Sprintf("meters.calibration[%s]: invalid units %q; using linear", k, c.Units))
+			c.Units = MeterUnitsLinear
+		}
+		if c.FloorDB >= 0 {
+			c.FloorDB = defaultMeterFloorDB
+		}
+		cfg.Meters.Calibration[k] = c
+	}
+	switch cfg.Meters.Ballistics.Mode {
+	case MeterModePeak, MeterModeVU, MeterModePPM:
+	default:
+		cfg.Meta.Warnings = append(cfg.Meta.Warnings, fmt.Sprintf("invalid meters.ballistics.mode %q; using peak", cfg.Meters.Ballistics.Mode))
+		cfg.Meters.Ballistics.Mode = MeterModePeak
+	}
+	if len(cfg.Meters.History.Meters) == 0 {
+		for _, id := range meterRCIDs {
+			cfg.Meters.History.Meters = append(cfg.Meters.History.Meters, strconv.Itoa(id))
+		}
+	}
+	for _, k := range cfg.Meters.History.Meters {
+		if id, err := resolveRC(k); err != nil || !isMeterRC(id) {
+			cfg.Meta.Warnings = append(cfg.Meta.Warnings, fmt.Sprintf("meters.history.meters: unknown meter %q ignored", k))
+		}
+	}
+	if cfg.Meters.History.Keep1s <= 0 {
+		cfg.Meters.History.Keep1s = 3600
+	}
+	if cfg.Meters.History.Keep1m <= 0 {
+		cfg.Meters.History.Keep1m = 1440
+	}
+	if cfg.Meters.History.Keep1h <= 0 {
+		cfg.Meters.History.Keep1h = 720
+	}
+	cfg.Mock.Model = strings.ToLower(strings.TrimSpace(cfg.Mock.Model))
+	switch cfg.Mock.Model {
+	case "":
+		cfg.Mock.Model = MockModelMixer
+	case MockModelMixer, MockModelRandom:
+	default:
+		cfg.Meta.Warnings = append(cfg.Meta.Warnings, fmt.Sprintf("invalid mock.model %q; using mixer", cfg.Mock.Model))
+		cfg.Mock.Model = MockModelMixer
+	}
+	if cfg.Alarms.Silence.ThresholdDB == nil {
+		def := cfg.Alarms.Silence.threshold()
+		cfg.Alarms.Silence.ThresholdDB = &def
+	}
+	if cfg.Alarms.Silence.DurationS <= 0 {
+		cfg.Alarms.Silence.DurationS = 10
+	}
+	if cfg.Alarms.Silence.HysteresisDB <= 0 {
+		cfg.Alarms.Silence.HysteresisDB = 3
+	}
+	if cfg.Alarms.Silence.RecoveryS <= 0 {
+		cfg.Alarms.Silence.RecoveryS = 2
+	}
+	if cfg.Alarms.Over.CeilingDB == nil {
+		def := cfg.Alarms.Over.ceiling()
+		cfg.Alarms.Over.CeilingDB = &def
+	}
+	if cfg.Alarms.Over.HoldMs <= 0 {
+		cfg.Alarms.Over.HoldMs = 250
+	}
+	if cfg.Alarms.Over.HysteresisDB <= 0 {
+		cfg.Alarms.Over.HysteresisDB = 1
+	}
+	if cfg.Alarms.Over.RecoveryMs <= 0 {
+		cfg.Alarms.Over.RecoveryMs = 500
+	}
 	if cfg.Admin.PIN == "" {
 		cfg.Admin.PIN = "CHANGE_ME"
 	}
+	cfg.Auth.AnonymousRole = strings.ToLower(strings.TrimSpace(cfg.Auth.AnonymousRole))
+	if cfg.Auth.AnonymousRole == "" {
+		cfg.Auth.AnonymousRole = RoleViewer
+	}
+	if cfg.Auth.AnonymousRole != RoleNone && roleRank(cfg.Auth.AnonymousRole) == 0 {
+		cfg.Meta.Warnings = append(cfg.Meta.Warnings, fmt.Sprintf("invalid auth.anonymous_role %q; using viewer", cfg.Auth.AnonymousRole))
+		cfg.Auth.AnonymousRole = RoleViewer
+	}
+	for i := range cfg.Auth.Tokens {
+		t := &cfg.Auth.Tokens[i]
+		t.Role = strings.ToLower(strings.TrimSpace(t.Role))
+		if roleRank(t.Role) == 0 {
+			cfg.Meta.Warnings = append(cfg.Meta.Warnings, fmt.Sprintf("auth.tokens[%d] (%s): invalid role %q; using viewer", i, t.Name, t.Role))
+			t.Role = RoleViewer
+		}
+	}
 
 	if cfg.Updates.Mode == "" {
 		cfg.Updates.Mode = "git"
@@ -144,8 +540,10 @@ func LoadConfig(path string) (*Config, error) {
 	// NOTE: The caller passes the YAML config path in via `path`.
 	// We use that same path when deciding whether config.json overrides
 	// are applicable (newer than the YAML, etc.).
-	applyJSONOverrides(&cfg, path)
-	applyEnvOverrides(&cfg)
+	if overrides {
+		applyJSONOverrides(&cfg, path)
+		applyEnvOverrides(&cfg)
+	}
 
 	// Backward compatibility:
 	// Some earlier releases briefly wrote the requested mode to the deprecated
@@ -173,7 +571,7 @@ func LoadConfig(path string) (*Config, error) {
 	// Normalize/validate mode.
 	cfg.DSP.Mode = strings.ToLower(strings.TrimSpace(cfg.DSP.Mode))
 	switch cfg.DSP.Mode {
-	case "mock", "live":
+	case "mock", "live", DSPModeShadow:
 		// ok
 	default:
 		cfg.Meta.Warnings = append(cfg.Meta.Warnings, fmt.Sprintf("invalid STUDIOB_UI_MODE %q; forcing mock", cfg.DSP.Mode))
@@ -160,8 +160,11 @@ func ValidateEditableConfig(c EditableConfig) error {
 	if strings.HasPrefix(m, "mock") {
 		m = "mock"
 	}
-	if m != "" && m != "mock" && m != "live" {
-		return fmt.Errorf("mode must be 'mock' or 'live' (got %q)", c.Mode)
+	if strings.HasPrefix(m, DSPModeShadow) {
+		m = DSPModeShadow
+	}
+	if m != "" && m != "mock" && m != "live" && m != DSPModeShadow {
+		return fmt.Errorf("mode must be 'mock', 'shadow' or 'live' (got %q)", c.Mode)
 	}
 	if strings.TrimSpace(c.DSP.IP) != "" {
 		if ip := net.ParseIP(strings.TrimSpace(c.DSP.IP)); ip == nil {
@@ -226,6 +229,9 @@ func WriteEditableConfig(c EditableConfig) (string, error) {
 		if strings.HasPrefix(nm, "mock") {
 			nm = "mock"
 		}
+		if strings.HasPrefix(nm, DSPModeShadow) {
+			nm = DSPModeShadow
+		}
 		// Keep both the new (dsp.mode) and legacy (mode) fields in sync.
 		//
 		// The UI reads/writes dsp.mode, but older versions (and some operator
@@ -0,0 +1,189 @@
+package app
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// ---------------------------------------------------------------------------
+// Per-control write policies (v0.3.76)
+//
+// Any allowlisted RC used to accept any float from any caller, so a buggy
+// client could slam a fader from off to full in one write. Each control may
+// now carry a policy in config:
+//
+//	control_policies:
+//	  STUB_SPK_LEVEL:
+//	    min: 0
+//	    max: 0.9          # never above this position
+//	    max_step: 0.2     # largest change per write
+//	    role: operator    # minimum caller role
+//	  "101":
+//	    modes: [mock]     # mock-only until the fader is commissioned
+//
+// Rules (all optional):
+//
+//   - min / max: inclusive value bounds
+//   - max_step: largest |new - current| per write
+//   - role: minimum caller role (viewer | operator | admin)
+//   - modes: dsp.mode values the control may be written in ("simulate"
+//     counts as mock)
+//
+// SetRC and the speaker-mute intent check the policy before anything is
+// written. Non-finite values (NaN, ±Inf) are always refused. A refused
+// write is logged in state/intents.jsonl with "rejected": true and the rule,
+// and journaled as "control.rejected".
+// ---------------------------------------------------------------------------
+
+// ControlPolicy is one control's write rules and LIVE rollout flags.
+type ControlPolicy struct {
+	Min     *float64 `yaml:"min" json:"min,omitempty"`
+	Max     *float64 `yaml:"max" json:"max,omitempty"`
+	MaxStep float64  `yaml:"max_step" json:"maxStep,omitempty"`
+	Role    string   `yaml:"role" json:"role,omitempty"`
+	Modes   []string `yaml:"modes" json:"modes,omitempty"`
+	// Live and ECPName drive canary LIVE writes (v0.3.77, see dsp_canary.go).
+	Live    *bool  `yaml:"live" json:"live,omitempty"`
+	ECPName string `yaml:"ecp_name" json:"ecpName,omitempty"`
+	// Type is the expected Named Control type for the design conformance
+	// check (v0.3.81, see dsp_conformance.go).
+	Type string `yaml:"type" json:"type,omitempty"`
+}
+
+// PolicyError is a write refused by a control policy. Rule is one of
+// "value", "min", "max", "max_step", "role" or "mode".
+type PolicyError struct {
+	RC     int
+	Rule   string
+	Detail string
+}
+
+func (err *PolicyError) Error() string {
+	return fmt.Sprintf("rc %d: policy %s: %s", err.RC, err.Rule, err.Detail)
+}
+
+func policyMode(mode string) string {
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	if mode == "simulate" {
+		return "mock"
+	}
+	return mode
+}
+
+// normalizeControlPolicy cleans one config entry, appending warnings.
+func normalizeControlPolicy(key string, cp ControlPolicy, warnings *[]string) ControlPolicy {
+	if _, err := resolveRC(strings.TrimSpace(key)); err != nil {
+		*warnings = append(*warnings, fmt.Sprintf("control_policies: unknown control %q ignored", key))
+	}
+	if cp.Min != nil && cp.Max != nil && *cp.Min > *cp.Max {
+		*warnings = append(*warnings, fmt.Sprintf("control_policies[%s]: min %v > max %v; bounds ignored", key, *cp.Min, *cp.Max))
+		cp.Min, cp.Max = nil, nil
+	}
+	if cp.MaxStep < 0 {
+		*warnings = append(*warnings, fmt.Sprintf("control_policies[%s]: max_step %v < 0; ignored", key, cp.MaxStep))
+		cp.MaxStep = 0
+	}
+	cp.Role = strings.ToLower(strings.TrimSpace(cp.Role))
+	if cp.Role != "" && roleRank(cp.Role) == 0 {
+		*warnings = append(*warnings, fmt.Sprintf("control_policies[%s]: unknown role %q; using admin", key, cp.Role))
+		cp.Role = RoleAdmin
+	}
+	var modes []string
+	for _, m := range cp.Modes {
+		switch m = policyMode(m); m {
+		case "mock", "live", DSPModeShadow:
+			modes = append(modes, m)
+		default:
+			*warnings = append(*warnings, fmt.Sprintf("control_policies[%s]: unknown mode %q ignored", key, m))
+		}
+	}
+	cp.Modes = modes
+	cp.Type = strings.ToLower(strings.TrimSpace(cp.Type))
+	if !conformanceTypeValid(cp.Type) {
+		*warnings = append(*warnings, fmt.Sprintf("control_policies[%s]: unknown type %q ignored", key, cp.Type))
+		cp.Type = ""
+	}
+	return cp
+}
+
+// ControlPolicyFor returns the policy for RC id, if any.
+func (c Config) ControlPolicyFor(id int) (ControlPolicy, bool) {
+	for k, cp := range c.ControlPolicies {
+		if rc, err := resolveRC(strings.TrimSpace(k)); err == nil && rc == id {
+			return cp, true
+		}
+	}
+	return ControlPolicy{}, false
+}
+
+// checkControlPolicy returns a *PolicyError when writing value to id is not
+// allowed for who. current is the control's present value.
+func checkControlPolicy(cfg Config, id int, value, current float64, who Identity) error {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return &PolicyError{RC: id, Rule: "value", Detail: fmt.Sprintf("%v is not a finite number", value)}
+	}
+	cp, ok := cfg.ControlPolicyFor(id)
+	if !ok {
+		return nil
+	}
+	if cp.Role != "" && !RoleAtLeast(who.Role, cp.Role) {
+		role := who.Role
+		if role == "" {
+			role = RoleNone
+		}
+		return &PolicyError{RC: id, Rule: "role", Detail: fmt.Sprintf("requires %s (caller is %s)", cp.Role, role)}
+	}
+	if len(cp.Modes) > 0 {
+		mode := policyMode(cfg.DSP.Mode)
+		allowed := false
+		for _, m := range cp.Modes {
+			allowed = allowed || m == mode
+		}
+		if !allowed {
+			return &PolicyError{RC: id, Rule: "mode", Detail: fmt.Sprintf("not writable in %s mode (allowed: %s)", mode, strings.Join(cp.Modes, ", "))}
+		}
+	}
+	if cp.Min != nil && value < *cp.Min {
+		return &PolicyError{RC: id, Rule: "min", Detail: fmt.Sprintf("%v is below min %v", value, *cp.Min)}
+	}
+	if cp.Max != nil && value > *cp.Max {
+		return &PolicyError{RC: id, Rule: "max", Detail: fmt.Sprintf("%v is above max %v", value, *cp.Max)}
+	}
+	if cp.MaxStep > 0 && math.Abs(value-current) > cp.MaxStep+1e-9 {
+		return &PolicyError{RC: id, Rule: "max_step", Detail: fmt.Sprintf("step from %v to %v exceeds max_step %v", current, value, cp.MaxStep)}
+	}
+	return nil
+}
+
+// controlPolicyCheck checks a write of value to id against its policy and
+// logs a refusal as a rejected intent.
+func (e *Engine) controlPolicyCheck(action string, id int, value float64, who Identity, source string) error {
+	e.mu.RLock()
+	current := e.rc[id]
+	e.mu.RUnlock()
+	err := checkControlPolicy(e.GetConfigCopy(), id, value, current, who)
+	if err == nil {
+		return nil
+	}
+	pe := err.(*PolicyError)
+	if source == "" {
+		source = who.Name
+	}
+	details := map[string]any{
+		"rc":       id,
+		"value":    value,
+		"current":  current,
+		"rejected": true,
+		"rule":     pe.Rule,
+		"error":    pe.Error(),
+		"caller":   who.Name,
+		"role":     who.Role,
+	}
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		details["value"] = fmt.Sprint(value) // not a valid JSON number
+	}
+	_ = e.appendIntent(IntentEvent{Action: action, Source: source, Details: details})
+	e.RecordEvent("control.rejected", SeverityWarn, details)
+	return err
+}
@@ -0,0 +1,265 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Time-limited LIVE arming (v0.3.74)
+//
+// dsp.mode=live used to mean "writes enabled forever": DSPLiveActive only
+// looked at the mode and the link, so the armed flag did nothing. LIVE writes
+// now need an explicit, admin-gated arm with a time-to-live and a reason:
+//
+//	POST /api/dsp/arm     {"ttlSec": 900, "reason": "morning show"}
+//	POST /api/dsp/disarm  {"reason": "done"}
+//	GET  /api/dsp/arm     current arm status
+//
+// An arm lasts ttlSec (dsp.arm.default_ttl_sec when omitted, at most
+// dsp.arm.max_ttl_sec). Arming again while armed replaces the expiry. The
+// engine disarms on its own when:
+//
+//   - the TTL expires,
+//   - DSP health goes DISCONNECTED, or
+//   - the DSP config signature (mode, host, port, backups) changes.
+//
+// An engine restart also starts disarmed. Only an armed engine sends ECP
+// writes (ecpSendSet refuses otherwise) and DSPControlAllowed rejects
+// operator writes in LIVE mode until armed. Reads (health probes, reconnect
+// rehydration) are not gated.
+//
+// Arm and disarm need the admin role (session, token or PIN; see auth.go).
+// A missing reason or a TTL over the maximum is a bad request; the mode,
+// link and conformance preconditions are refusals (see DSPArmError).
+//
+// Every arm and disarm is appended to the intent audit log
+// (state/intents.jsonl, actions "dsp.arm" / "dsp.disarm") with the caller's
+// name and role, and journaled as "dsp.arm". Automatic disarms are recorded
+// as the "engine" caller.
+// ---------------------------------------------------------------------------
+
+// DSPArmStatus is the current LIVE arm state.
+type DSPArmStatus struct {
+	Armed        bool   `json:"armed"`
+	ArmedAt      string `json:"armedAt,omitempty"`
+	ExpiresAt    string `json:"expiresAt,omitempty"`
+	RemainingSec int    `json:"remainingSec,omitempty"`
+	Reason       string `json:"reason,omitempty"`
+	ArmedBy      string `json:"armedBy,omitempty"`
+	Role         string `json:"role,omitempty"`
+	// From dsp.arm, so the UI can offer sensible choices.
+	DefaultTTLSec int `json:"defaultTtlSec"`
+	MaxTTLSec     int `json:"maxTtlSec"`
+}
+
+// DSPArmRequest is the body of POST /api/dsp/arm.
+type DSPArmRequest struct {
+	TTLSec int    `json:"ttlSec"`
+	Reason string `json:"reason"`
+}
+
+// DSPArmError is an arm request that was refused. Rule is "request" for a
+// malformed request (reason, ttlSec) and "mode", "disconnected" or
+// "conformance" when the engine is not in a state that allows arming.
+type DSPArmError struct {
+	Rule   string
+	Detail string
+}
+
+func (err *DSPArmError) Error() string {
+	return "cannot arm: " + err.Detail
+}
+
+// BadRequest reports whether the request itself was at fault.
+func (err *DSPArmError) BadRequest() bool {
+	return err.Rule == "request"
+}
+
+// engineIdentity is the caller recorded for disarms the engine makes itself.
+var engineIdentity = Identity{Name: "engine", Via: "engine"}
+
+// dspArmedLocked reports whether LIVE writes are armed right now for sig.
+// Caller MUST hold e.dspMu.
+func (e *Engine) dspArmedLocked(now time.Time, sig string) bool {
+	return e.dspLiveArmed && now.Before(e.dspLiveArmUntil) && e.dspLiveArmSig == sig
+}
+
+// DSPLiveActive reports whether DSP writes are currently armed: desired mode
+// live, an unexpired arm for the current DSP config, and a link that is not
+// DISCONNECTED.
+func (e *Engine) DSPLiveActive() bool {
+	cfg := e.GetConfigCopy()
+	if !strings.EqualFold(strings.TrimSpace(cfg.DSP.Mode), "live") {
+		return false
+	}
+	sig := dspConfigSignatureFrom(&cfg)
+	e.ensureDSPHealthInit()
+	e.dspMu.Lock()
+	defer e.dspMu.Unlock()
+	return e.dspArmedLocked(time.Now(), sig) && e.dsp.state != DSPHealthDisconnected
+}
+
+// DSPArmStatus returns the arm state (Armed reflects DSPLiveActive).
+func (e *Engine) DSPArmStatus() DSPArmStatus {
+	cfg := e.GetConfigCopy()
+	st := DSPArmStatus{
+		Armed:         e.DSPLiveActive(),
+		DefaultTTLSec: cfg.DSP.Arm.DefaultTTLSec,
+		MaxTTLSec:     cfg.DSP.Arm.MaxTTLSec,
+	}
+	if !st.Armed {
+		return st
+	}
+	e.dspMu.Lock()
+	defer e.dspMu.Unlock()
+	st.ArmedAt = e.dspLiveArmedAt.UTC().Format(time.RFC3339)
+	st.ExpiresAt = e.dspLiveArmUntil.UTC().Format(time.RFC3339)
+	st.RemainingSec = int(time.Until(e.dspLiveArmUntil).Seconds())
+	st.Reason = e.dspLiveArmReason
+	st.ArmedBy = e.dspLiveArmBy
+	st.Role = e.dspLiveArmRole
+	return st
+}
+
+// ArmDSPLive arms LIVE writes for req.TTLSec seconds on behalf of who (the
+// caller must already be admin). Refusals are *DSPArmError.
+//
+// Preconditions (conservative):
+//   - desired mode (config) must be "live"
+//   - a reason must be given
+//   - the DSP must not be DISCONNECTED (based on cached health)
+//   - the design conformance check must pass (v0.3.81)
+func (e *Engine) ArmDSPLive(req DSPArmRequest, who Identity) (DSPArmStatus, error) {
+	cfg := e.GetConfigCopy()
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return DSPArmStatus{}, &DSPArmError{Rule: "request", Detail: "a reason is required"}
+	}
+	ttl := req.TTLSec
+	if ttl <= 0 {
+		ttl = cfg.DSP.Arm.DefaultTTLSec
+	}
+	if ttl > cfg.DSP.Arm.MaxTTLSec {
+		return DSPArmStatus{}, &DSPArmError{Rule: "request", Detail: fmt.Sprintf("ttlSec %d exceeds dsp.arm.max_ttl_sec %d", ttl, cfg.DSP.Arm.MaxTTLSec)}
+	}
+	if !strings.EqualFold(strings.TrimSpace(cfg.DSP.Mode), "live") {
+		return DSPArmStatus{}, &DSPArmError{Rule: "mode", Detail: fmt.Sprintf("config dsp.mode is %q (set to 'live' first)", cfg.DSP.Mode)}
+	}
+	if err := e.conformanceGate(cfg); err != nil {
+		return DSPArmStatus{}, &DSPArmError{Rule: "conformance", Detail: err.Error()}
+	}
+	sig := dspConfigSignatureFrom(&cfg)
+
+	e.ensureDSPHealthInit()
+	now := time.Now().UTC()
+	until := now.Add(time.Duration(ttl) * time.Second)
+	e.dspMu.Lock()
+	if e.dsp.state == DSPHealthDisconnected {
+		e.dspMu.Unlock()
+		return DSPArmStatus{}, &DSPArmError{Rule: "disconnected", Detail: "DSP is disconnected"}
+	}
+	extended := e.dspArmedLocked(now, sig)
+	e.dspLiveArmed = true
+	if !extended {
+		e.dspLiveArmedAt = now
+	}
+	e.dspLiveArmUntil = until
+	e.dspLiveArmReason = reason
+	e.dspLiveArmBy = who.Name
+	e.dspLiveArmRole = who.Role
+	e.dspLiveArmSig = sig
+	if e.dspLiveArmTimer != nil {
+		e.dspLiveArmTimer.Stop()
+	}
+	e.dspLiveArmTimer = time.AfterFunc(until.Sub(now), func() { e.expireDSPArm(until) })
+	e.dspMu.Unlock()
+
+	details := map[string]any{
+		"armed":     true,
+		"ttlSec":    ttl,
+		"expiresAt": until.Format(time.RFC3339),
+		"reason":    reason,
+		"extended":  extended,
+	}
+	e.auditDSPArm("dsp.arm", who, cfg.DSP.Mode, details)
+	e.RecordEvent("dsp.arm", SeverityWarn, details)
+	return e.DSPArmStatus(), nil
+}
+
+// DisarmDSPLive disables DSP write operations (safe fallback) on behalf of
+// who. It reports whether the engine was armed.
+func (e *Engine) DisarmDSPLive(reason string, who Identity) bool {
+	e.ensureDSPHealthInit()
+	e.dspMu.Lock()
+	was := e.dspLiveArmed
+	armedAt := e.dspLiveArmedAt
+	e.dspLiveArmed = false
+	e.dspLiveArmedAt = time.Time{}
+	e.dspLiveArmUntil = time.Time{}
+	e.dspLiveArmReason = ""
+	e.dspLiveArmBy = ""
+	e.dspLiveArmRole = ""
+	e.dspLiveArmSig = ""
+	if e.dspLiveArmTimer != nil {
+		e.dspLiveArmTimer.Stop()
+		e.dspLiveArmTimer = nil
+	}
+	e.dspMu.Unlock()
+
+	if !was {
+		return false
+	}
+	details := map[string]any{
+		"armed":   false,
+		"reason":  reason,
+		"armedAt": armedAt.UTC().Format(time.RFC3339),
+	}
+	e.auditDSPArm("dsp.disarm", who, e.GetConfigCopy().DSP.Mode, details)
+	e.RecordEvent("dsp.arm", SeverityInfo, details)
+	return true
+}
+
+// expireDSPArm runs from the arm timer. until identifies the arm it was set
+// for, so a re-arm that replaced it is left alone.
+func (e *Engine) expireDSPArm(until time.Time) {
+	e.dspMu.Lock()
+	current := e.dspLiveArmed && e.dspLiveArmUntil.Equal(until)
+	e.dspMu.Unlock()
+	if current {
+		e.DisarmDSPLive("expired", engineIdentity)
+	}
+}
+
+// disarmIfDSPConfigChanged disarms when the running config no longer matches
+// the one the arm was granted for (or is no longer live).
+func (e *Engine) disarmIfDSPConfigChanged() {
+	cfg := e.GetConfigCopy()
+	sig := dspConfigSignatureFrom(&cfg)
+	e.dspMu.Lock()
+	changed := e.dspLiveArmed && e.dspLiveArmSig != sig
+	e.dspMu.Unlock()
+	if !changed {
+		return
+	}
+	if !strings.EqualFold(strings.TrimSpace(cfg.DSP.Mode), "live") {
+		e.DisarmDSPLive("dsp.mode is "+cfg.DSP.Mode, engineIdentity)
+		return
+	}
+	e.DisarmDSPLive("dsp config changed", engineIdentity)
+}
+
+// auditDSPArm appends an arm/disarm record to the intent audit log, naming
+// the resolved caller rather than anything the client claimed.
+func (e *Engine) auditDSPArm(action string, who Identity, mode string, details map[string]any) {
+	details["caller"] = who.Name
+	details["role"] = who.Role
+	_ = e.appendIntent(IntentEvent{
+		TS:      time.Now().UTC().Format(time.RFC3339),
+		Action:  action,
+		Source:  who.Name,
+		Mode:    strings.ToLower(strings.TrimSpace(mode)),
+		Details: details,
+	})
+}
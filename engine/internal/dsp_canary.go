@@ -0,0 +1,171 @@
+package app
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Canary LIVE mode (v0.3.77)
+//
+// Controls come live one at a time (speaker mute first, then the Host
+// fader), but dsp.mode was all-or-nothing. With dsp.mode=live, only
+// controls flagged live in control_policies send real ECP writes:
+//
+//	control_policies:
+//	  STUB_SPK_MUTE:
+//	    live: true
+//	  "101":
+//	    live: true
+//	    ecp_name: STUB_MIC_HOST_GAIN   # Named Control for an unnamed RC
+//
+// Every other control keeps mock behaviour in LIVE mode (RC cache only) and
+// each write is logged to state/intents.jsonl as a "dsp.write" with
+// "mock": true, so the rollout can be rehearsed on air.
+//
+// STUB_SPK_MUTE is live by default (it has been the LIVE control since
+// v0.2.76); set live: false to take it back to mock. A live control needs a
+// Named Control: its STUB_* name or ecp_name.
+//
+// The RC cache holds positions (0..1), so booleans are written with "csv"
+// and all other controls with "csp" (ecpSetCommand); a fader at 0.5 never
+// becomes a +0.5 dB gain.
+//
+// Writes to live controls still need an armed engine (dsp_arming.go).
+// DSPModeStatus lists the live controls.
+// ---------------------------------------------------------------------------
+
+// LiveControl is one control with real DSP writes in LIVE mode.
+type LiveControl struct {
+	RC      int    `json:"rc"`
+	ECPName string `json:"ecpName,omitempty"`
+	// Error is set when the control cannot be written (e.g. no Named Control).
+	Error string `json:"error,omitempty"`
+}
+
+// controlLive reports whether RC id sends real writes in LIVE mode.
+func (c Config) controlLive(id int) bool {
+	if cp, ok := c.ControlPolicyFor(id); ok && cp.Live != nil {
+		return *cp.Live
+	}
+	return id == rcNameToID["STUB_SPK_MUTE"]
+}
+
+// ecpControlName is the Named Control for RC id: ecp_name, else its STUB_*
+// name, else "".
+func (c Config) ecpControlName(id int) string {
+	if cp, ok := c.ControlPolicyFor(id); ok && strings.TrimSpace(cp.ECPName) != "" {
+		return strings.TrimSpace(cp.ECPName)
+	}
+	for name, rc := range rcNameToID {
+		if rc == id {
+			return name
+		}
+	}
+	return ""
+}
+
+// LiveControls lists the allowlisted controls flagged live, by RC id.
+func (c Config) LiveControls() []LiveControl {
+	out := []LiveControl{}
+	for _, id := range c.RCAllowlist {
+		if isMeterRC(id) || !c.controlLive(id) {
+			continue
+		}
+		lc := LiveControl{RC: id, ECPName: c.ecpControlName(id)}
+		if lc.ECPName == "" {
+			lc.Error = "no Named Control (set ecp_name)"
+		}
+		out = append(out, lc)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].RC < out[j].RC })
+	return out
+}
+
+// ecpSetCommand is the ECP line that writes val (an RC cache value, in
+// position units) to RC id's Named Control. Booleans take "csv" (value and
+// position are both 0/1); everything else takes "csp", because "csv" sets
+// the control's value, which is in dB for gains (0.5 would mean +0.5 dB).
+func (c Config) ecpSetCommand(id int, name string, val float64) string {
+	if c.conformanceType(id, name) == "boolean" {
+		return ecpCSVCommand(name, val)
+	}
+	return ecpCSPCommand(name, val)
+}
+
+// dspWriteControl performs the DSP side of a control write in LIVE mode: a
+// real ECP write for live controls, a logged mock write for the others. It
+// does not touch the RC cache; on error the caller must not either.
+func (e *Engine) dspWriteControl(cfg Config, id int, val float64, source string) error {
+	name := cfg.ecpControlName(id)
+	if !cfg.controlLive(id) {
+		return e.appendIntent(IntentEvent{
+			Action: "dsp.write",
+			Source: source,
+			Details: map[string]any{
+				"rc":     id,
+				"name":   name,
+				"value":  val,
+				"ok":     true,
+				"mock":   true,
+				"detail": "control not live (canary)",
+			},
+		})
+	}
+	if name == "" {
+		return fmt.Errorf("dsp write failed: rc %d has no Named Control (set control_policies ecp_name)", id)
+	}
+	if strings.EqualFold(strings.TrimSpace(cfg.DSP.Mode), DSPModeShadow) {
+		return e.dspShadowWrite(cfg, id, name, val, source)
+	}
+
+	// Attempt the write first; the caller updates the cache only on success.
+	cmd := cfg.ecpSetCommand(id, name, val)
+	e.dspWriteMu.Lock()
+	resp, werr := e.ecpSendSet(cmd, 1200*time.Millisecond)
+	e.dspWriteMu.Unlock()
+	// Always append an explicit write audit record, even on failure.
+	wev := IntentEvent{
+		TS:     time.Now().UTC().Format(time.RFC3339),
+		Action: "dsp.write",
+		Source: source,
+		Details: map[string]any{
+			"rc":      id,
+			"name":    name,
+			"value":   val,
+			"ok":      (werr == nil),
+			"resp":    resp,
+			"command": cmd,
+			"target":  e.activeDSPEndpoint(cfg).addr(),
+		},
+	}
+	if werr != nil {
+		wev.Details["error"] = werr.Error()
+	}
+	if err := e.appendIntent(wev); err != nil {
+		// Logging failure must be visible.
+		return fmt.Errorf("dsp write log failed: %w", err)
+	}
+	// Record the attempt for the Engineering UI.
+	st := &DSPWriteStatus{
+		TS:    wev.TS,
+		Name:  name,
+		RC:    id,
+		Value: val,
+		Ok:    (werr == nil),
+		Resp:  resp,
+		Mode:  "live",
+	}
+	if werr != nil {
+		st.Error = werr.Error()
+	}
+	e.setLastDSPWrite(st)
+	if werr != nil {
+		return fmt.Errorf("dsp write failed: %w", werr)
+	}
+	log.Printf("dsp write OK: %s=%v (resp=%s)", name, val, resp)
+	return nil
+}
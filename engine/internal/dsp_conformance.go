@@ -0,0 +1,359 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Named control design conformance (v0.3.81)
+//
+// If someone renamed STUB_SPK_MUTE in the Q-SYS design, the engine only found
+// out when an on-air write failed. The conformance check reads every
+// allowlisted control's Named Control (STUB_* name or ecp_name) with "cg" and
+// reports:
+//
+//   - missing: the Core does not know the name (bad_id, ...)
+//   - type_mismatch: the reply does not fit the control's type. The type is
+//     control_policies.<ctl>.type, else derived from the name: *MUTE is
+//     "boolean" (value 0/1), *LEVEL / *GAIN is "level" (a "dB" string),
+//     meters are "meter" (position 0..1); "any" skips the check
+//   - read_only: only on an explicit admin request
+//     (POST /api/dsp/conformance {"writeProbe": true}) with
+//     dsp.conformance.write_probe set and LIVE writes armed, the position
+//     just read is written back with "csp" on the same connection, while
+//     engine writes are held off (dspWriteMu), so nothing is reverted. A
+//     refusal means the control is read-only. Meters are skipped; otherwise
+//     the check is reported as unchecked. The automatic triggers (connect,
+//     arm) never write.
+//   - unknown: ECP cannot list a design's Named Controls, so discovery uses
+//     dsp.conformance.design_controls (names exported from Q-SYS Designer):
+//     names with our prefix (default STUB_) that the engine does not know
+//     are read and reported when present on the Core. Unknown controls are
+//     warnings only.
+//
+// The check runs when the link comes up (LIVE and shadow modes) and on
+// demand (POST /api/dsp/conformance, admin); GET returns the last report.
+// Any missing, read-only or mismatched control blocks arming LIVE writes;
+// ArmDSPLive re-runs a check that is missing or stale for the current
+// config. Each run is journaled as "dsp.conformance".
+// ---------------------------------------------------------------------------
+
+const (
+	ConformanceOK           = "ok"
+	ConformanceMissing      = "missing"
+	ConformanceReadOnly     = "read_only"
+	ConformanceTypeMismatch = "type_mismatch"
+	ConformanceUnnamed      = "unnamed"
+	ConformanceUnknown      = "unknown"
+	ConformanceError        = "error"
+)
+
+// DSPConformancePolicy configures the check (see dsp_conformance.go).
+type DSPConformancePolicy struct {
+	// Prefix marks our Named Controls (default "STUB_").
+	Prefix string `yaml:"prefix" json:"prefix"`
+	// DesignControls lists the design's Named Controls for discovery.
+	DesignControls []string `yaml:"design_controls" json:"designControls,omitempty"`
+	// WriteProbe writes each read value back to detect read-only controls.
+	WriteProbe bool `yaml:"write_probe" json:"writeProbe"`
+}
+
+// ConformanceControl is one checked Named Control.
+type ConformanceControl struct {
+	RC       int    `json:"rc,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Status   string `json:"status"`
+	Writable string `json:"writable,omitempty"` // "yes", "no" or "unchecked"
+	Reply    string `json:"reply,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+}
+
+// DSPConformanceReport is the result of one conformance run.
+type DSPConformanceReport struct {
+	At       string               `json:"at"`
+	Trigger  string               `json:"trigger"`
+	Target   string               `json:"target,omitempty"`
+	OK       bool                 `json:"ok"`
+	Checked  int                  `json:"checked"`
+	Problems int                  `json:"problems"`
+	Controls []ConformanceControl `json:"controls"`
+	Unknown  []ConformanceControl `json:"unknown,omitempty"`
+	Error    string               `json:"error,omitempty"`
+	// ProbeNote says why a requested write probe did not run.
+	ProbeNote string `json:"probeNote,omitempty"`
+	sig       string
+}
+
+// dspConformance is stored on Engine: runMu serializes runs, mu guards the
+// last report.
+type dspConformance struct {
+	runMu sync.Mutex
+	mu    sync.Mutex
+	last  *DSPConformanceReport
+}
+
+// conformanceType is the expected type of RC id's Named Control.
+func (c Config) conformanceType(id int, name string) string {
+	if cp, ok := c.ControlPolicyFor(id); ok && cp.Type != "" {
+		return cp.Type
+	}
+	n := strings.ToUpper(name)
+	switch {
+	case isMeterRC(id):
+		return "meter"
+	case strings.HasSuffix(n, "MUTE"):
+		return "boolean"
+	case strings.HasSuffix(n, "LEVEL") || strings.HasSuffix(n, "GAIN"):
+		return "level"
+	}
+	return "any"
+}
+
+// conformanceTargets lists the allowlisted controls to check, by RC id.
+func (c Config) conformanceTargets() []ConformanceControl {
+	var out []ConformanceControl
+	for _, id := range c.RCAllowlist {
+		name := c.ecpControlName(id)
+		cc := ConformanceControl{RC: id, Name: name, Type: c.conformanceType(id, name)}
+		if name == "" {
+			cc.Status = ConformanceUnnamed
+			cc.Detail = "no Named Control (set control_policies ecp_name)"
+		}
+		out = append(out, cc)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].RC < out[j].RC })
+	return out
+}
+
+// conformanceSig changes whenever the check would look at something else.
+func conformanceSig(cfg Config) string {
+	var names []string
+	for _, cc := range cfg.conformanceTargets() {
+		names = append(names, fmt.Sprintf("%d=%s:%s", cc.RC, cc.Name, cc.Type))
+	}
+	cp := cfg.DSP.Conformance
+	return fmt.Sprintf("%s|%s|%s|%t|%s", dspConfigSignatureFrom(&cfg), strings.Join(names, ","),
+		cp.Prefix, cp.WriteProbe, strings.Join(cp.DesignControls, ","))
+}
+
+// checkConformanceType returns why reply r does not fit type typ, or "".
+func checkConformanceType(typ string, r *ECPReply) string {
+	switch typ {
+	case "boolean":
+		if r.Value == nil || (*r.Value != 0 && *r.Value != 1) {
+			return "expected a boolean (value 0 or 1)"
+		}
+	case "level":
+		if r.Value == nil || !strings.HasSuffix(strings.ToLower(strings.TrimSpace(r.String)), "db") {
+			return fmt.Sprintf("expected a level in dB (string %q)", r.String)
+		}
+	case "meter":
+		if r.Position == nil || *r.Position < 0 || *r.Position > 1 {
+			return "expected a meter (position 0..1)"
+		}
+	}
+	return ""
+}
+
+// ecpWriteBackProbe reads name and, when the reply fits typ, writes the same
+// position back on the same connection. It holds dspWriteMu so no engine
+// write lands in between, and writes only while LIVE writes are armed.
+func (e *Engine) ecpWriteBackProbe(name, typ string, timeout time.Duration) (line, wline string, wrote bool, err error) {
+	e.dspWriteMu.Lock()
+	defer e.dspWriteMu.Unlock()
+	err = e.ecpSession(timeout, func(send func(string) (string, error)) error {
+		var err error
+		if line, err = send("cg " + name); err != nil {
+			return err
+		}
+		r := parseECPReply(line)
+		if r.Kind != "cv" || r.Position == nil || checkConformanceType(typ, r) != "" || !e.DSPLiveActive() {
+			return nil
+		}
+		if wline, err = send(ecpCSPCommand(name, *r.Position)); err != nil {
+			return fmt.Errorf("write probe: %w", err)
+		}
+		wrote = true
+		return nil
+	})
+	return line, wline, wrote, err
+}
+
+// checkNamedControl reads one control and fills in its status; with probe
+// it also checks writability (see ecpWriteBackProbe).
+func (e *Engine) checkNamedControl(cc *ConformanceControl, probe bool, timeout time.Duration) {
+	var line, wline string
+	var wrote bool
+	var err error
+	if probe && cc.Type != "meter" {
+		line, wline, wrote, err = e.ecpWriteBackProbe(cc.Name, cc.Type, timeout)
+	} else {
+		line, err = e.ecpExchange("cg "+cc.Name, timeout)
+	}
+	cc.Reply = line
+	if err != nil {
+		cc.Status, cc.Detail = ConformanceError, err.Error()
+		return
+	}
+	r := parseECPReply(line)
+	if r.Kind != "cv" {
+		cc.Status, cc.Detail = ConformanceMissing, "not found on the Core"
+		return
+	}
+	if why := checkConformanceType(cc.Type, r); why != "" {
+		cc.Status, cc.Detail = ConformanceTypeMismatch, why
+		return
+	}
+	cc.Status, cc.Writable = ConformanceOK, "unchecked"
+	if !wrote {
+		return
+	}
+	switch {
+	case !strings.HasPrefix(wline, "cv "):
+		cc.Status, cc.Writable, cc.Detail = ConformanceReadOnly, "no", "write probe refused: "+wline
+	default:
+		cc.Writable = "yes"
+	}
+}
+
+// RunDSPConformance checks the design against the engine's controls and
+// stores the report. trigger is "connect", "api" or "arm"; who is the caller
+// journaled with the result (engineIdentity for automatic runs). writeProbe
+// is only ever set by the admin API.
+func (e *Engine) RunDSPConformance(trigger string, writeProbe bool, who Identity) (DSPConformanceReport, error) {
+	cfg := e.GetConfigCopy()
+	if !dspModeWrites(cfg.DSP.Mode) {
+		return DSPConformanceReport{}, fmt.Errorf("dsp.mode is %q; conformance needs live or shadow", cfg.DSP.Mode)
+	}
+	c := e.conformance
+	c.runMu.Lock()
+	defer c.runMu.Unlock()
+
+	timeout := time.Duration(cfg.DSP.Health.TimeoutMs) * time.Millisecond
+	rep := DSPConformanceReport{
+		At:       time.Now().UTC().Format(time.RFC3339),
+		Trigger:  trigger,
+		Target:   e.activeDSPEndpoint(cfg).addr(),
+		Controls: cfg.conformanceTargets(),
+		sig:      conformanceSig(cfg),
+	}
+	probe := false
+	if writeProbe {
+		switch {
+		case !cfg.DSP.Conformance.WriteProbe:
+			rep.ProbeNote = "dsp.conformance.write_probe is off"
+		case !e.DSPLiveActive():
+			rep.ProbeNote = "LIVE writes are not armed"
+		default:
+			probe = true
+		}
+	}
+	known := map[string]bool{}
+	for i := range rep.Controls {
+		cc := &rep.Controls[i]
+		if cc.Status == ConformanceUnnamed {
+			continue
+		}
+		known[strings.ToUpper(cc.Name)] = true
+		e.checkNamedControl(cc, probe, timeout)
+		rep.Checked++
+		if cc.Status == ConformanceError && rep.Error == "" {
+			rep.Error = cc.Detail
+		}
+		if cc.Status != ConformanceOK {
+			rep.Problems++
+		}
+	}
+	prefix := strings.ToUpper(cfg.DSP.Conformance.Prefix)
+	for _, name := range cfg.DSP.Conformance.DesignControls {
+		name = strings.TrimSpace(name)
+		if !strings.HasPrefix(strings.ToUpper(name), prefix) || known[strings.ToUpper(name)] {
+			continue
+		}
+		known[strings.ToUpper(name)] = true
+		line, err := e.ecpExchange("cg "+name, timeout)
+		if err == nil && strings.HasPrefix(line, "cv ") {
+			rep.Unknown = append(rep.Unknown, ConformanceControl{Name: name, Status: ConformanceUnknown, Reply: line, Detail: "on the Core but not known to the engine"})
+		}
+	}
+	rep.OK = rep.Problems == 0
+
+	c.mu.Lock()
+	c.last = &rep
+	c.mu.Unlock()
+
+	sev := SeverityInfo
+	if !rep.OK {
+		sev = SeverityWarn
+	}
+	e.RecordEvent("dsp.conformance", sev, map[string]any{
+		"trigger":    trigger,
+		"caller":     who.Name,
+		"role":       who.Role,
+		"writeProbe": probe,
+		"ok":         rep.OK,
+		"checked":    rep.Checked,
+		"problems":   rep.Problems,
+		"unknown":    len(rep.Unknown),
+		"summary":    rep.summary(),
+	})
+	return rep, nil
+}
+
+// summary lists the failing controls, e.g. "STUB_SPK_MUTE missing".
+func (r DSPConformanceReport) summary() string {
+	var parts []string
+	for _, cc := range r.Controls {
+		if cc.Status != ConformanceOK && cc.Status != ConformanceUnnamed {
+			parts = append(parts, cc.Name+" "+cc.Status)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// DSPConformance returns the last report, or nil before the first run.
+func (e *Engine) DSPConformance() *DSPConformanceReport {
+	c := e.conformance
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.last == nil {
+		return nil
+	}
+	r := *c.last
+	return &r
+}
+
+// conformanceGate is the arming precondition: a passing report for the
+// current config, running the check first when none exists.
+func (e *Engine) conformanceGate(cfg Config) error {
+	rep := e.DSPConformance()
+	if rep == nil || rep.sig != conformanceSig(cfg) {
+		r, err := e.RunDSPConformance("arm", false, engineIdentity)
+		if err != nil {
+			return err
+		}
+		rep = &r
+	}
+	if !rep.OK {
+		detail := rep.summary()
+		if rep.Error != "" && detail == "" {
+			detail = rep.Error
+		}
+		return fmt.Errorf("design conformance failed (%d of %d controls: %s)", rep.Problems, rep.Checked, detail)
+	}
+	return nil
+}
+
+// conformanceTypeValid reports whether t is a known control type.
+func conformanceTypeValid(t string) bool {
+	switch t {
+	case "", "any", "boolean", "level", "meter":
+		return true
+	}
+	return false
+}
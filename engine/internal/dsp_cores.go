@@ -0,0 +1,186 @@
+package app
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Primary / backup Q-SYS Core failover (v0.3.71)
+//
+// A redundant Core pair used to look like a dead DSP: the engine only knew
+// dsp.host:dsp.port and kept probing (and writing to) the primary after the
+// backup took over. The endpoint list is now:
+//
+//	dsp.host:dsp.port, then dsp.backups[] in order
+//
+// With more than one endpoint, every health probe connects to each Core and
+// asks for its redundancy status with the ECP "sg" command:
+//
+//	sg  ->  sr "<design name>" "<design code>" <is primary> <is active>
+//
+// The first Core that reports active becomes the active Core. If none
+// answers "sg" usefully but one is reachable, the first reachable Core is
+// used. ECP writes go to the active Core. (There are no ECP change-group
+// subscriptions in this tree; anything added later should dial
+// activeDSPEndpoint too.)
+//
+// A change of active Core is a failover: it is recorded in the DSP health
+// timeline (event "failover") and journaled as "dsp.failover".
+//
+// A single endpoint keeps the old behavior: TCP connect only, no commands.
+// ---------------------------------------------------------------------------
+
+// DSPEndpoint is one Q-SYS Core ECP endpoint.
+type DSPEndpoint struct {
+	Name string `yaml:"name" json:"name,omitempty"`
+	Host string `yaml:"host" json:"host"`
+	Port int    `yaml:"port" json:"port"`
+}
+
+func (ep DSPEndpoint) addr() string {
+	return net.JoinHostPort(ep.Host, itoa(ep.Port))
+}
+
+func (ep DSPEndpoint) label() string {
+	if ep.Name != "" {
+		return ep.Name
+	}
+	return ep.addr()
+}
+
+// DSPEndpoints returns the ordered Core list (primary first).
+func (c Config) DSPEndpoints() []DSPEndpoint {
+	out := []DSPEndpoint{{Name: "primary", Host: strings.TrimSpace(c.DSP.Host), Port: c.DSP.Port}}
+	return append(out, c.DSP.Backups...)
+}
+
+// DSPCoreStatus is one Core as seen by the latest probe.
+type DSPCoreStatus struct {
+	DSPEndpoint
+	Reachable bool   `json:"reachable"`
+	Primary   *bool  `json:"primary,omitempty"` // from sg; nil when unknown
+	Active    *bool  `json:"active,omitempty"`
+	Design    string `json:"design,omitempty"`
+	Error     string `json:"error,omitempty"`
+	Selected  bool   `json:"selected"` // the Core writes go to
+}
+
+// dspProbeResult is gathered without holding e.dspMu.
+type dspProbeResult struct {
+	cores      []DSPCoreStatus
+	active     int // index into cores, -1 when nothing is reachable
+	connectRTT time.Duration
+	err        error
+}
+
+// probeDSPCores connects to every endpoint (sg only with more than one).
+// dial is Engine.dialECP so probes appear in the transcript (v0.3.79).
+func probeDSPCores(cfg Config, timeout time.Duration, dial func(string, time.Duration) (net.Conn, error)) dspProbeResult {
+	eps := cfg.DSPEndpoints()
+	res := dspProbeResult{active: -1, cores: make([]DSPCoreStatus, len(eps))}
+	rtts := make([]time.Duration, len(eps))
+	firstReachable := -1
+	var errs []string
+	for i, ep := range eps {
+		st := DSPCoreStatus{DSPEndpoint: ep}
+		start := time.Now()
+		c, err := dial(ep.addr(), timeout)
+		if err != nil {
+			st.Error = err.Error()
+			errs = append(errs, ep.label()+": "+err.Error())
+			res.cores[i] = st
+			continue
+		}
+		rtts[i] = time.Since(start)
+		st.Reachable = true
+		if firstReachable < 0 {
+			firstReachable = i
+		}
+		if len(eps) > 1 {
+			if err := readCoreStatus(c, timeout, &st); err != nil {
+				st.Error = err.Error()
+			} else if res.active < 0 && st.Active != nil && *st.Active {
+				res.active = i
+			}
+		}
+		_ = c.Close()
+		res.cores[i] = st
+	}
+	if res.active < 0 {
+		res.active = firstReachable
+	}
+	if res.active < 0 {
+		res.err = fmt.Errorf("no DSP core reachable (%s)", strings.Join(errs, "; "))
+		if len(eps) == 1 && len(errs) == 1 {
+			res.err = fmt.Errorf("%s", strings.TrimPrefix(errs[0], eps[0].label()+": "))
+		}
+		return res
+	}
+	res.cores[res.active].Selected = true
+	res.connectRTT = rtts[res.active]
+	return res
+}
+
+// readCoreStatus sends "sg" and parses the "sr" reply into st.
+func readCoreStatus(c net.Conn, timeout time.Duration, st *DSPCoreStatus) error {
+	_ = c.SetDeadline(time.Now().Add(timeout))
+	if _, err := c.Write([]byte("sg\n")); err != nil {
+		return err
+	}
+	line, err := bufio.NewReader(c).ReadString('\n')
+	if err != nil {
+		return err
+	}
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "sr ") {
+		return fmt.Errorf("unexpected sg reply: %s", line)
+	}
+	f := strings.Fields(line)
+	if len(f) < 3 {
+		return fmt.Errorf("short sg reply: %s", line)
+	}
+	primary, active := f[len(f)-2] == "1", f[len(f)-1] == "1"
+	st.Primary, st.Active = &primary, &active
+	if q := strings.SplitN(line, `"`, 3); len(q) == 3 {
+		st.Design = q[1]
+	}
+	return nil
+}
+
+// applyDSPProbeLocked stores the probe's Core view and reports a failover
+// (old, new label) when the active Core changed. Caller MUST hold e.dspMu.
+func (e *Engine) applyDSPProbeLocked(res dspProbeResult) (from, to string, failover bool) {
+	e.dsp.cores = res.cores
+	if res.active < 0 {
+		// Keep the last active Core: writes stay pointed at it and a later
+		// recovery elsewhere still counts as a failover.
+		return "", "", false
+	}
+	next := res.cores[res.active].DSPEndpoint
+	prev := e.dsp.activeCore
+	e.dsp.activeCore = &next
+	if prev == nil || prev.addr() == next.addr() {
+		return "", "", false
+	}
+	return prev.label(), next.label(), true
+}
+
+// activeDSPEndpoint is the Core writes should go to: the active Core when
+// known, otherwise the primary.
+func (e *Engine) activeDSPEndpoint(cfg Config) DSPEndpoint {
+	e.ensureDSPHealthInit()
+	e.dspMu.Lock()
+	defer e.dspMu.Unlock()
+	if ep := e.dsp.activeCore; ep != nil {
+		for _, known := range cfg.DSPEndpoints() {
+			if known.addr() == ep.addr() {
+				return known
+			}
+		}
+	}
+	return cfg.DSPEndpoints()[0]
+}
@@ -12,13 +12,15 @@ import (
 // Q-SYS External Control Protocol (ECP) helper (v0.2.76)
 //
 // StudioB-UI's v0.2.x branch intentionally keeps DSP control conservative.
-// We currently use ONLY one ECP write path (Speaker Mute) and only when:
-//   - cfg.DSP.Mode == "live"
+// ECP writes go only to controls flagged live (Speaker Mute by default;
+// canary controls since v0.3.77, see dsp_canary.go) and only when:
+//   - cfg.DSP.Mode == "live" and LIVE writes are armed (v0.3.74)
 //   - DSP health is not DISCONNECTED (enforced by DSPControlAllowed)
 //
 // Why ECP?
 // - It's a simple, line-oriented TCP protocol supported by Q-SYS Core.
-// - It lets us set a Named Control value using `csv <name> <value>`.
+// - It lets us set a Named Control value using `csv <name> <value>`, or its
+//   position (0..1, the RC cache unit) using `csp <name> <position>`.
 //
 // IMPORTANT SAFETY NOTES:
 // - We create a short-lived TCP connection per command.
@@ -27,7 +29,7 @@ import (
 //   so failures remain visible to the operator.
 // ---------------------------------------------------------------------------
 
-// ecpSendCSV sets a named control's *value* using the ECP "csv" command.
+// ecpSendSet sends one set command ("csv" / "csp", see Config.ecpSetCommand).
 //
 // Example command:
 //
@@ -39,12 +41,59 @@ import (
 //
 // NOTE: We do not attempt to parse the full cv payload in v0.2.x.
 // We only need a reliable success/failure signal.
-func (e *Engine) ecpSendCSV(controlName string, value float64, timeout time.Duration) (string, error) {
+func (e *Engine) ecpSendSet(cmd string, timeout time.Duration) (string, error) {
+	// v0.3.74: only an armed engine writes to the Core.
+	if !e.DSPLiveActive() {
+		return "", fmt.Errorf("LIVE writes are not armed")
+	}
+	// Q-SYS ECP is line-oriented. We terminate with \n.
+	line, err := e.ecpExchange(cmd, timeout)
+	if err != nil {
+		return line, err
+	}
+	if strings.HasPrefix(line, "cv ") {
+		return line, nil
+	}
+	// Anything else is treated as an error (bad_command, not_found, etc.).
+	return line, fmt.Errorf("ecp error: %s", line)
+}
+
+// ecpCSVCommand is the "csv" line (without the newline) that sets a named
+// control's value; shadow mode logs it instead of sending (v0.3.78).
+func ecpCSVCommand(controlName string, value float64) string {
+	return fmt.Sprintf("csv %s %v", controlName, value)
+}
+
+// ecpCSPCommand is the "csp" line that sets a named control's position
+// (0..1), the unit of the engine's RC cache.
+func ecpCSPCommand(controlName string, position float64) string {
+	return fmt.Sprintf("csp %s %v", controlName, position)
+}
+
+// ecpExchange sends one ECP command line to the active Core and returns the
+// single (trimmed) response line. Split out of the write path in v0.3.73 so
+// reads ("cg") share the same connection handling.
+func (e *Engine) ecpExchange(cmd string, timeout time.Duration) (string, error) {
+	var line string
+	err := e.ecpSession(timeout, func(send func(string) (string, error)) error {
+		var err error
+		line, err = send(cmd)
+		return err
+	})
+	return line, err
+}
+
+// ecpSession runs fn on one connection to the active Core; send writes a
+// command line and returns its (trimmed) response line. The conformance
+// write probe uses it to read and write back without a gap (v0.3.81).
+func (e *Engine) ecpSession(timeout time.Duration, fn func(send func(string) (string, error)) error) error {
 	cfg := e.GetConfigCopy()
-	host := strings.TrimSpace(cfg.DSP.Host)
-	port := cfg.DSP.Port
+	// v0.3.71: follow the active Core of a redundant pair (dsp_cores.go).
+	ep := e.activeDSPEndpoint(cfg)
+	host := strings.TrimSpace(ep.Host)
+	port := ep.Port
 	if host == "" || port == 0 {
-		return "", fmt.Errorf("DSP host/port not configured")
+		return fmt.Errorf("DSP host/port not configured")
 	}
 
 	if timeout <= 0 {
@@ -52,32 +101,55 @@ func (e *Engine) ecpSendCSV(controlName string, value float64, timeout time.Dura
 	}
 
 	addr := net.JoinHostPort(host, itoa(port))
-	c, err := net.DialTimeout("tcp", addr, timeout)
+	dialStart := time.Now()
+	c, err := e.dialECP(addr, timeout)
 	if err != nil {
-		return "", err
+		return err
 	}
 	defer c.Close()
+	connectRTT := time.Since(dialStart)
 
 	// A single deadline covers both the write and the read.
 	_ = c.SetDeadline(time.Now().Add(timeout))
 
-	// Q-SYS ECP is line-oriented. We terminate with \n.
-	cmd := fmt.Sprintf("csv %s %v\n", controlName, value)
-	if _, err := c.Write([]byte(cmd)); err != nil {
-		return "", err
+	r := bufio.NewReader(c)
+	send := func(cmd string) (string, error) {
+		cmdStart := time.Now()
+		if _, err := c.Write([]byte(cmd + "\n")); err != nil {
+			e.recordDSPLatency(connectRTT, 0)
+			return "", err
+		}
+		// Read one response line.
+		line, err := r.ReadString('\n')
+		if err != nil {
+			e.recordDSPLatency(connectRTT, 0)
+			return "", err
+		}
+		// v0.3.68: any response line (even an error) is a completed round trip.
+		e.recordDSPLatency(connectRTT, time.Since(cmdStart))
+		connectRTT = 0 // one connect sample per connection
+		return strings.TrimSpace(line), nil
 	}
+	return fn(send)
+}
 
-	// Read one response line.
-	// On success, Q-SYS returns a single "cv ..." line.
-	r := bufio.NewReader(c)
-	line, err := r.ReadString('\n')
+// ecpGetControl reads a named control with "cg" (v0.3.73). The reply is
+//
+//	cv "<name>" "<string>" <value> <position>
+//
+// parsed by parseECPReply (ecp_console.go); we return value and position.
+func (e *Engine) ecpGetControl(controlName string, timeout time.Duration) (value, position float64, err error) {
+	line, err := e.ecpExchange("cg "+controlName, timeout)
 	if err != nil {
-		return "", err
+		return 0, 0, err
 	}
-	line = strings.TrimSpace(line)
-	if strings.HasPrefix(line, "cv ") {
-		return line, nil
+	rep := parseECPReply(line)
+	if rep.Kind != "cv" {
+		return 0, 0, fmt.Errorf("ecp error: %s", line)
 	}
-	// Anything else is treated as an error (bad_command, not_found, etc.).
-	return line, fmt.Errorf("ecp error: %s", line)
+	if rep.Value == nil || rep.Position == nil {
+		return 0, 0, fmt.Errorf("malformed cv reply: %s", line)
+	}
+	value, position = *rep.Value, *rep.Position
+	return value, position, nil
 }
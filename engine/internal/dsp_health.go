@@ -3,7 +3,7 @@ package app
 import (
 	"bufio"
 	"encoding/json"
-	"net"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -39,6 +39,18 @@ type DSPHealthSnapshot struct {
 	ConsecutiveFailures int    `json:"consecutiveFailures"`
 	LastError           string `json:"lastError,omitempty"`
 	LastTestAt          string `json:"lastTestAt,omitempty"`
+
+	// v0.3.67: recovery progress and the state machine parameters in effect.
+	ConsecutiveSuccesses int             `json:"consecutiveSuccesses"`
+	Reason               string          `json:"reason,omitempty"`
+	Policy               DSPHealthPolicy `json:"policy"`
+
+	// v0.3.68: rolling connect / command round-trip statistics.
+	Latency DSPLatency `json:"latency"`
+
+	// v0.3.71: per-Core status and the Core writes go to (see dsp_cores.go).
+	ActiveCore string          `json:"activeCore,omitempty"`
+	Cores      []DSPCoreStatus `json:"cores,omitempty"`
 }
 
 // dspHealth is stored on Engine and guarded by dspMu.
@@ -48,8 +60,19 @@ type dspHealth struct {
 	lastOK     time.Time
 	lastPollAt time.Time
 	failures   int
+	successes  int
+	reason     string
+	latency    map[string]*latencyWindow
 	lastErr    string
 	lastTestAt time.Time
+
+	// v0.3.71: latest per-Core probe and the active Core (nil = unknown).
+	cores      []DSPCoreStatus
+	activeCore *DSPEndpoint
+
+	// v0.3.65: mock scenario override ("" = healthy mock DSP).
+	simState DSPHealthState
+	simErr   string
 }
 
 func (e *Engine) ensureDSPHealthInit() {
@@ -69,10 +92,19 @@ func (e *Engine) ensureDSPHealthInit() {
 // Keep this intentionally boring and explicit; this code runs in a hot path
 // (the 2s DSP monitor loop) and must never block on I/O.
 func (e *Engine) dspHealthSnapshotLocked() DSPHealthSnapshot {
+	policy := e.GetConfigCopy().DSP.Health
 	snap := DSPHealthSnapshot{
-		State:               e.dsp.state,
-		Connected:           e.dsp.connected,
-		ConsecutiveFailures: e.dsp.failures,
+		State:                e.dsp.state,
+		Connected:            e.dsp.connected,
+		ConsecutiveFailures:  e.dsp.failures,
+		ConsecutiveSuccesses: e.dsp.successes,
+		Reason:               e.dsp.reason,
+		Policy:               policy,
+		Latency:              e.dspLatencyLocked(policy),
+		Cores:                append([]DSPCoreStatus(nil), e.dsp.cores...),
+	}
+	if e.dsp.activeCore != nil {
+		snap.ActiveCore = e.dsp.activeCore.label()
 	}
 	if !e.dsp.lastOK.IsZero() {
 		snap.LastOK = e.dsp.lastOK.UTC().Format(time.RFC3339)
@@ -112,10 +144,13 @@ func (e *Engine) DSPHealthSnapshot() DSPHealthSnapshot {
 // TestDSPConnectivity performs a single bounded TCP connect to the configured DSP host/port.
 //
 // Why TCP connect?
-// - It is protocol-agnostic, so we don't risk sending malformed commands.
-// - It reliably tells us whether the DSP endpoint is reachable on the network.
+//   - It reliably tells us whether the DSP endpoint is reachable on the network.
+//   - With a single Core nothing is sent. With backups configured (v0.3.71) each
+//     Core is also asked for its redundancy status with the read-only ECP "sg"
+//     command (see dsp_cores.go); the probe never writes a control.
 //
-// This is NOT polling. It runs only when explicitly requested (UI button).
+// It runs on request (UI button, reconnect) and from dspMonitorLoop every
+// dsp.health.poll_ms; timeout 0 uses dsp.health.timeout_ms.
 func (e *Engine) TestDSPConnectivity(timeout time.Duration) DSPHealthSnapshot {
 	e.ensureDSPHealthInit()
 	cfg := e.GetConfigCopy()
@@ -130,35 +165,43 @@ func (e *Engine) TestDSPConnectivity(timeout time.Duration) DSPHealthSnapshot {
 		prev := e.dsp.state
 		e.dsp.lastTestAt = now
 		e.dsp.lastPollAt = now
-		e.dsp.connected = true
-		e.dsp.state = DSPHealthOK
-		e.dsp.lastOK = now
-		e.dsp.failures = 0
-		e.dsp.lastErr = ""
+		switch e.dsp.simState {
+		case "", DSPHealthOK:
+			// Recovery from a simulated fault follows the real state machine.
+			e.recordDSPProbeLocked(now, cfg.DSP.Health, nil)
+		default:
+			// v0.3.65: a mock scenario is simulating a DSP fault.
+			e.dsp.connected = e.dsp.simState != DSPHealthDisconnected
+			e.dsp.state = e.dsp.simState
+			e.dsp.failures++
+			e.dsp.successes = 0
+			e.dsp.reason = "simulated by mock scenario"
+			e.dsp.lastErr = "simulated: " + e.dsp.simErr
+			if strings.TrimSpace(e.dsp.simErr) == "" {
+				e.dsp.lastErr = "simulated: " + strings.ToLower(string(e.dsp.simState))
+			}
+		}
 		if e.dsp.state != prev {
 			// Record the state transition for operator visibility.
 			e.appendDSPTimelineLocked(now)
 		}
+		snap := e.dspHealthSnapshotLocked()
 		e.dspMu.Unlock()
-		return e.DSPHealth()
+		e.publishDSPHealthChange(prev, snap)
+		return snap
 	}
 
-	host := strings.TrimSpace(cfg.DSP.Host)
-	port := cfg.DSP.Port
-
-	// Default conservative timeout if caller passes 0.
+	// Caller passes 0 to use dsp.health.timeout_ms (v0.3.67).
 	if timeout <= 0 {
-		timeout = 1200 * time.Millisecond
+		timeout = time.Duration(cfg.DSP.Health.TimeoutMs) * time.Millisecond
 	}
 
 	now := time.Now()
-	addr := net.JoinHostPort(host, itoa(port))
 
-	// NOTE: we do NOT hold e.dspMu during the network call.
-	c, err := net.DialTimeout("tcp", addr, timeout)
-	if err == nil {
-		_ = c.Close()
-	}
+	// NOTE: we do NOT hold e.dspMu during the network calls.
+	// v0.3.71: every configured Core is probed (see dsp_cores.go).
+	probe := probeDSPCores(cfg, timeout, e.dialECP)
+	err := probe.err
 
 	e.dspMu.Lock()
 	// NOTE: Do NOT call e.DSPHealth() while holding this lock.
@@ -166,17 +209,21 @@ func (e *Engine) TestDSPConnectivity(timeout time.Duration) DSPHealthSnapshot {
 	//
 	// This exact bug caused /api/health and /api/version to hang in LIVE mode
 	// because the always-on DSP monitor loop calls TestDSPConnectivity() every
-	// 2 seconds.
+	// dsp.health.poll_ms.
 
+	prev := e.dsp.state
 	e.dsp.lastTestAt = now
 	e.dsp.lastPollAt = now
 
 	if err == nil {
-		e.dsp.connected = true
-		e.dsp.state = DSPHealthOK
-		e.dsp.lastOK = now
-		e.dsp.failures = 0
-		e.dsp.lastErr = ""
+		e.recordDSPLatencyLocked(LatencyConnect, probe.connectRTT, cfg.DSP.Health)
+	}
+	failFrom, failTo, failover := e.applyDSPProbeLocked(probe)
+	if failover {
+		e.appendDSPFailoverLocked(now, failFrom, failTo)
+	}
+	e.recordDSPProbeLocked(now, cfg.DSP.Health, err)
+	if err == nil {
 		// v0.2.52: mark validation time when in LIVE mode
 		mode := strings.ToLower(strings.TrimSpace(cfg.DSP.Mode))
 		if mode == "live" {
@@ -184,23 +231,94 @@ func (e *Engine) TestDSPConnectivity(timeout time.Duration) DSPHealthSnapshot {
 			// v0.2.55: capture the DSP config signature used for this validation.
 			e.dspValidatedConfigSig = e.dspConfigSignature()
 		}
-	} else {
+	}
+	if e.dsp.state != prev {
+		// v0.3.69: live transitions were never persisted, which left the
+		// outage report empty outside mock mode.
+		e.appendDSPTimelineLocked(now)
+	}
+
+	snap := e.dspHealthSnapshotLocked()
+	e.dspMu.Unlock()
+	if failover {
+		e.RecordEvent("dsp.failover", SeverityWarn, map[string]any{"from": failFrom, "to": failTo})
+	}
+	e.publishDSPHealthChange(prev, snap)
+	if snap.State == DSPHealthDisconnected && prev != DSPHealthDisconnected {
+		// v0.3.74: losing the link ends the LIVE arm; re-arming is explicit.
+		e.DisarmDSPLive("dsp disconnected", engineIdentity)
+	}
+	if snap.State == DSPHealthOK && prev != DSPHealthOK {
+		// v0.3.81: check the design whenever the link comes up.
+		go func() { _, _ = e.RunDSPConformance("connect", false, engineIdentity) }()
+	}
+	return snap
+}
+
+// recordDSPProbeLocked advances the health state machine by one probe result.
+// Caller MUST hold e.dspMu.
+//
+//   - failures: OK -> DEGRADED after degrade_after, DISCONNECTED after
+//     disconnect_after consecutive failures
+//   - successes: UNKNOWN/OK -> OK at once; after a failure the link stays
+//     DEGRADED ("recovering") until recover_after consecutive successes, so a
+//     flapping link does not bounce the UI between OK and DISCONNECTED
+func (e *Engine) recordDSPProbeLocked(now time.Time, p DSPHealthPolicy, err error) {
+	if err != nil {
+		e.dsp.successes = 0
 		e.dsp.failures++
 		e.dsp.lastErr = err.Error()
-		// Conservative state machine:
-		// - First/second failure: DEGRADED
-		// - Third+ consecutive failure: DISCONNECTED
-		if e.dsp.failures >= 3 {
+		switch {
+		case e.dsp.failures >= p.DisconnectAfter:
 			e.dsp.connected = false
 			e.dsp.state = DSPHealthDisconnected
-		} else {
+			e.dsp.reason = fmt.Sprintf("%d consecutive failures", e.dsp.failures)
+		case e.dsp.failures >= p.DegradeAfter:
 			e.dsp.state = DSPHealthDegraded
+			e.dsp.reason = fmt.Sprintf("%d consecutive failures", e.dsp.failures)
 		}
+		return
+	}
+	e.dsp.failures = 0
+	e.dsp.successes++
+	e.dsp.connected = true
+	e.dsp.lastOK = now
+	// v0.3.68: a reachable but slow link is DEGRADED, not OK.
+	if slow := e.dspSlowReasonLocked(p); slow != "" {
+		e.dsp.state = DSPHealthDegraded
+		e.dsp.reason = slow
+		return
+	}
+	if e.dsp.state == DSPHealthUnknown || e.dsp.state == DSPHealthOK || e.dsp.successes >= p.RecoverAfter {
+		e.dsp.state = DSPHealthOK
+		e.dsp.lastErr = ""
+		e.dsp.reason = ""
+		return
 	}
+	e.dsp.state = DSPHealthDegraded
+	e.dsp.reason = fmt.Sprintf("recovering: %d/%d successful probes", e.dsp.successes, p.RecoverAfter)
+}
 
-	snap := e.dspHealthSnapshotLocked()
-	e.dspMu.Unlock()
-	return snap
+// publishDSPHealthChange emits a "dsp.health" event on the events topic when
+// the state changed. Caller MUST NOT hold e.dspMu.
+func (e *Engine) publishDSPHealthChange(prev DSPHealthState, snap DSPHealthSnapshot) {
+	if prev == snap.State {
+		return
+	}
+	sev := SeverityInfo
+	switch snap.State {
+	case DSPHealthDisconnected:
+		sev = SeverityError
+	case DSPHealthDegraded:
+		sev = SeverityWarn
+	}
+	e.RecordEvent("dsp.health", sev, map[string]any{
+		"from":      prev,
+		"to":        snap.State,
+		"failures":  snap.ConsecutiveFailures,
+		"lastError": snap.LastError,
+		"reason":    snap.Reason,
+	})
 }
 
 // DSPControlAllowed answers: "should we accept an operator RC write?"
@@ -209,19 +327,36 @@ func (e *Engine) TestDSPConnectivity(timeout time.Duration) DSPHealthSnapshot {
 //   - UI already blocks control attempts when DISCONNECTED.
 //   - This server-side check prevents silent no-op controls if UI is stale
 //     (cached JS) or a non-UI client calls the API.
-func (e *Engine) DSPControlAllowed() (bool, string) {
+//
+// v0.3.75: ctl (RC id or name) and the caller are checked against the show
+// lock first (see show_lock.go).
+func (e *Engine) DSPControlAllowed(ctl string, who Identity) (bool, string) {
+	if err := e.CheckShowLock(ctl, who); err != nil {
+		return false, err.Error()
+	}
 	e.ensureDSPHealthInit()
 	// In simulate mode, there is no external DSP; always allow.
 	mode := strings.ToLower(strings.TrimSpace(e.GetConfigCopy().DSP.Mode))
 	if mode == "simulate" || mode == "mock" {
+		// v0.3.65: except while a mock scenario simulates an outage, so drills
+		// see the same guard as the live studio.
+		e.dspMu.Lock()
+		defer e.dspMu.Unlock()
+		if e.dsp.simState == DSPHealthDisconnected {
+			return false, "DSP is disconnected (" + e.dsp.lastErr + ")"
+		}
 		return true, ""
 	}
 
-	// v0.2.76 clarification:
-	// This project does not expose a separate "arming" UI/API for LIVE mode.
-	// If Engineering sets dsp.mode=live, writes are allowed immediately (subject
-	// to the DISCONNECTED guard below). This matches the project's philosophy:
-	// explicit state > hidden automation.
+	// v0.3.74: LIVE writes need an explicit, time-limited arm
+	// (POST /api/dsp/arm, see dsp_arming.go). dsp.mode=live alone no longer
+	// enables them. v0.3.77: only for controls flagged live; the others
+	// stay mock (see dsp_canary.go). v0.3.78: shadow mode sends nothing, so
+	// it needs no arm.
+	cfg := e.GetConfigCopy()
+	rc, rcErr := resolveRC(strings.TrimSpace(ctl))
+	needArm := mode != DSPModeShadow && (rcErr != nil || cfg.controlLive(rc))
+	sig := dspConfigSignatureFrom(&cfg)
 
 	e.dspMu.Lock()
 	defer e.dspMu.Unlock()
@@ -229,6 +364,9 @@ func (e *Engine) DSPControlAllowed() (bool, string) {
 	if e.dsp.state == DSPHealthDisconnected {
 		return false, "DSP is disconnected (run 'Test DSP Now' to confirm link)"
 	}
+	if needArm && !e.dspArmedLocked(time.Now(), sig) {
+		return false, "LIVE writes are not armed (admin: arm via POST /api/dsp/arm)"
+	}
 	return true, ""
 }
 
@@ -263,16 +401,25 @@ func itoa(v int) string {
 //
 // IMPORTANT SAFETY PROPERTIES:
 // - The timeline is written ONLY when DSP health STATE CHANGES.
-// - The file is bounded (last 200 lines) to avoid unbounded disk growth.
+// - The file is bounded (dspTimelineMaxLines) to avoid unbounded disk growth.
 // - This does NOT talk to the DSP. Only TestDSPConnectivity does a TCP connect.
 // - If stateDir is unavailable, we fail silently (visibility-only feature).
+//
+// v0.3.69 raised the bound from 200 to 2000 lines so the outage report
+// (dsp_outages.go) can cover several weeks.
 type dspTimelineEntry struct {
 	Time      string         `json:"time"`
 	State     DSPHealthState `json:"state"`
 	Failures  int            `json:"failures"`
 	LastError string         `json:"last_error,omitempty"`
+	// v0.3.71: active Core, and "failover" lines ("from X to Y" in Detail).
+	Core   string `json:"core,omitempty"`
+	Event  string `json:"event,omitempty"`
+	Detail string `json:"detail,omitempty"`
 }
 
+const dspTimelineMaxLines = 2000
+
 func (e *Engine) dspTimelinePath() string {
 	if strings.TrimSpace(e.stateDir) == "" {
 		return ""
@@ -281,6 +428,15 @@ func (e *Engine) dspTimelinePath() string {
 }
 
 func (e *Engine) appendDSPTimelineLocked(now time.Time) {
+	e.writeDSPTimelineLocked(now, "", "")
+}
+
+// appendDSPFailoverLocked records a change of active Core (v0.3.71).
+func (e *Engine) appendDSPFailoverLocked(now time.Time, from, to string) {
+	e.writeDSPTimelineLocked(now, "failover", "from "+from+" to "+to)
+}
+
+func (e *Engine) writeDSPTimelineLocked(now time.Time, event, detail string) {
 	// Caller must hold e.dspMu and must have updated e.dsp.* already.
 	path := e.dspTimelinePath()
 	if path == "" {
@@ -293,6 +449,11 @@ func (e *Engine) appendDSPTimelineLocked(now time.Time) {
 		State:     e.dsp.state,
 		Failures:  e.dsp.failures,
 		LastError: e.dsp.lastErr,
+		Event:     event,
+		Detail:    detail,
+	}
+	if e.dsp.activeCore != nil {
+		ent.Core = e.dsp.activeCore.label()
 	}
 
 	// Append one line (JSONL).
@@ -304,14 +465,14 @@ func (e *Engine) appendDSPTimelineLocked(now time.Time) {
 	}
 
 	// Bound the file (best-effort). If this fails, we do not error out.
-	e.boundDSPTimeline(path, 200)
+	e.boundDSPTimeline(path, dspTimelineMaxLines)
 }
 
 func (e *Engine) boundDSPTimeline(path string, maxLines int) {
 	if maxLines <= 0 {
 		return
 	}
-	// Read all lines (file is intended to be small; max 200).
+	// Read all lines (file is intended to be small; max dspTimelineMaxLines).
 	f, err := os.Open(path)
 	if err != nil {
 		return
@@ -378,28 +539,29 @@ func (e *Engine) ReadDSPTimeline(n int) []dspTimelineEntry {
 //	operator to click "Test DSP Now".
 //
 // Safety properties:
-//   - This loop performs ONLY the same bounded TCP connectivity check used by
-//     TestDSPConnectivity(). It does NOT send DSP control commands.
+//   - This loop performs ONLY the same bounded probe as TestDSPConnectivity():
+//     a TCP connect, plus the read-only "sg" status query per Core when backups
+//     are configured (dsp_cores.go). The probe never writes a control; the
+//     conformance check it starts when the link comes up only reads them (cg).
 //   - Write controls remain governed by mode (mock blocks writes, live allows writes)
 //     and the existing server-side guard.
 //   - The loop runs inside the engine process and updates the cached DSP health
 //     snapshot so /api/dsp/health can display current status.
 //
 // Behavior:
-// - Poll interval: 2 seconds
-// - Connect timeout: 1.2 seconds (conservative, avoids thread pile-ups)
+// - Poll interval: dsp.health.poll_ms (default 2000, at least 250)
+// - Connect timeout: dsp.health.timeout_ms (default 1200; short, avoids thread pile-ups)
+// - Both are read on every iteration, so config reloads apply immediately.
 // - When the engine context is canceled, the loop exits cleanly.
 // ---------------------------------------------------------------------------
 func (e *Engine) dspMonitorLoop() { // This loop intentionally runs for the lifetime of the engine process.
 	// StudioB-UI is managed by systemd; a clean stop is handled by process exit.
 	//
-	// We keep the loop bounded (short timeout) and low-rate (2s) to avoid resource issues.
-	t := time.NewTicker(2 * time.Second)
-	defer t.Stop()
-
+	// We keep the loop bounded (short timeout) and low-rate to avoid resource issues.
+	// v0.3.67: interval and timeout come from dsp.health and follow config reloads.
 	for {
-		<-t.C
+		time.Sleep(time.Duration(e.GetConfigCopy().DSP.Health.PollMs) * time.Millisecond)
 		// Run a single bounded check. This updates the cached DSP health in-memory.
-		_ = e.TestDSPConnectivity(1200 * time.Millisecond)
+		_ = e.TestDSPConnectivity(0)
 	}
 }
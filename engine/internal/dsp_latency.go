@@ -0,0 +1,144 @@
+package app
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// DSP round-trip latency (v0.3.68)
+//
+// The health snapshot used to say only "connected or not", so a Core link
+// that was getting slow looked fine until it failed. We now time:
+//
+//	connect  TCP connect on every health probe and every ECP write
+//	command  ECP write -> response line (csv -> cv) on every write
+//
+// Each kind keeps a rolling window (dsp.health.latency_window samples) and
+// reports last / p50 / p95 / max and jitter (mean absolute difference between
+// consecutive samples). When either p95 reaches dsp.health.latency_degrade_ms
+// the next successful probe leaves the link DEGRADED with the figure in
+// "reason" instead of OK.
+// ---------------------------------------------------------------------------
+
+const (
+	LatencyConnect = "connect"
+	LatencyCommand = "command"
+)
+
+// LatencyStats summarizes one latency window (milliseconds).
+type LatencyStats struct {
+	Samples  int     `json:"samples"`
+	LastMs   float64 `json:"lastMs"`
+	P50Ms    float64 `json:"p50Ms"`
+	P95Ms    float64 `json:"p95Ms"`
+	MaxMs    float64 `json:"maxMs"`
+	JitterMs float64 `json:"jitterMs"`
+}
+
+// DSPLatency is the latency block of DSPHealthSnapshot.
+type DSPLatency struct {
+	Connect   LatencyStats `json:"connect"`
+	Command   LatencyStats `json:"command"`
+	DegradeMs int          `json:"degradeMs"`
+}
+
+// latencyWindow is a bounded sample window. Guarded by e.dspMu.
+type latencyWindow struct {
+	samples []float64
+}
+
+func (w *latencyWindow) add(ms float64, size int) {
+	w.samples = append(w.samples, ms)
+	if len(w.samples) > size {
+		w.samples = w.samples[len(w.samples)-size:]
+	}
+}
+
+func (w *latencyWindow) stats() LatencyStats {
+	n := len(w.samples)
+	if n == 0 {
+		return LatencyStats{}
+	}
+	sorted := append([]float64(nil), w.samples...)
+	sort.Float64s(sorted)
+	rank := func(p float64) float64 {
+		i := int(math.Ceil(p*float64(n))) - 1
+		return sorted[max(0, min(i, n-1))]
+	}
+	var jitter float64
+	for i := 1; i < n; i++ {
+		jitter += math.Abs(w.samples[i] - w.samples[i-1])
+	}
+	if n > 1 {
+		jitter /= float64(n - 1)
+	}
+	r := func(v float64) float64 { return math.Round(v*100) / 100 }
+	return LatencyStats{
+		Samples:  n,
+		LastMs:   r(w.samples[n-1]),
+		P50Ms:    r(rank(0.50)),
+		P95Ms:    r(rank(0.95)),
+		MaxMs:    r(sorted[n-1]),
+		JitterMs: r(jitter),
+	}
+}
+
+// recordDSPLatencyLocked adds one sample. Caller MUST hold e.dspMu.
+func (e *Engine) recordDSPLatencyLocked(kind string, d time.Duration, p DSPHealthPolicy) {
+	if e.dsp.latency == nil {
+		e.dsp.latency = map[string]*latencyWindow{}
+	}
+	w, ok := e.dsp.latency[kind]
+	if !ok {
+		w = &latencyWindow{}
+		e.dsp.latency[kind] = w
+	}
+	w.add(float64(d)/float64(time.Millisecond), p.LatencyWindow)
+}
+
+// recordDSPLatency is the lock-taking form used by the ECP write path.
+func (e *Engine) recordDSPLatency(connect, command time.Duration) {
+	e.ensureDSPHealthInit()
+	p := e.GetConfigCopy().DSP.Health
+	e.dspMu.Lock()
+	defer e.dspMu.Unlock()
+	if connect > 0 {
+		e.recordDSPLatencyLocked(LatencyConnect, connect, p)
+	}
+	if command > 0 {
+		e.recordDSPLatencyLocked(LatencyCommand, command, p)
+	}
+}
+
+// dspLatencyLocked returns the latency block. Caller MUST hold e.dspMu.
+func (e *Engine) dspLatencyLocked(p DSPHealthPolicy) DSPLatency {
+	out := DSPLatency{DegradeMs: p.LatencyDegradeMs}
+	if w := e.dsp.latency[LatencyConnect]; w != nil {
+		out.Connect = w.stats()
+	}
+	if w := e.dsp.latency[LatencyCommand]; w != nil {
+		out.Command = w.stats()
+	}
+	return out
+}
+
+// dspSlowReasonLocked reports why latency alone degrades the link, or "".
+// Caller MUST hold e.dspMu.
+func (e *Engine) dspSlowReasonLocked(p DSPHealthPolicy) string {
+	if p.LatencyDegradeMs <= 0 {
+		return ""
+	}
+	for _, kind := range []string{LatencyConnect, LatencyCommand} {
+		w := e.dsp.latency[kind]
+		if w == nil {
+			continue
+		}
+		if st := w.stats(); st.P95Ms >= float64(p.LatencyDegradeMs) {
+			return fmt.Sprintf("slow link: %s p95 %.0f ms >= %d ms", kind, st.P95Ms, p.LatencyDegradeMs)
+		}
+	}
+	return ""
+}
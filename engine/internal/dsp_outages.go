@@ -0,0 +1,257 @@
+package app
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// DSP uptime / outage report (v0.3.69)
+//
+// ReadDSPTimeline returns raw state changes; this turns them into numbers an
+// operator can hand to management:
+//
+//   - outages: every interval the link was not OK (DEGRADED or DISCONNECTED),
+//     with start, end, duration, worst state and the last error seen
+//   - per-day and per-week (Monday 00:00 UTC) availability: OK time as a
+//     percentage of observed time
+//   - MTBF (OK time / outages) and MTTR (mean outage duration) over the
+//     report window. An outage already running at the window start counts
+//     once, with only its in-window downtime.
+//
+// Notes:
+//   - Each timeline line holds until the next one (the last until now). Time
+//     before the first line is "unobserved" and excluded from availability.
+//   - The engine does not record its own downtime, so a gap while it was
+//     stopped is attributed to the state it last recorded.
+//   - Coverage is limited by the timeline bound (dspTimelineMaxLines).
+// ---------------------------------------------------------------------------
+
+// DSPOutage is one contiguous non-OK interval.
+type DSPOutage struct {
+	Start      string         `json:"start"`
+	End        string         `json:"end,omitempty"` // empty while ongoing
+	DurationS  float64        `json:"durationS"`
+	WorstState DSPHealthState `json:"worstState"`
+	LastError  string         `json:"lastError,omitempty"`
+	Ongoing    bool           `json:"ongoing,omitempty"`
+}
+
+// DSPAvailability is one day or week of the report.
+type DSPAvailability struct {
+	Start           string   `json:"start"`
+	ObservedS       float64  `json:"observedS"`
+	OkS             float64  `json:"okS"`
+	DegradedS       float64  `json:"degradedS"`
+	DisconnectedS   float64  `json:"disconnectedS"`
+	Outages         int      `json:"outages"`
+	AvailabilityPct *float64 `json:"availabilityPct"` // null when nothing was observed
+}
+
+// DSPOutageReport is returned by GET /api/dsp/outages.
+type DSPOutageReport struct {
+	Ok      bool              `json:"ok"`
+	From    string            `json:"from"`
+	To      string            `json:"to"`
+	Outages []DSPOutage       `json:"outages"`
+	Daily   []DSPAvailability `json:"daily"`
+	Weekly  []DSPAvailability `json:"weekly"`
+
+	AvailabilityPct *float64 `json:"availabilityPct"`
+	MTBFS           *float64 `json:"mtbfS"` // null until an outage has been seen
+	MTTRS           *float64 `json:"mttrS"`
+}
+
+// dspSpan is one timeline line held until the next.
+type dspSpan struct {
+	from, to time.Time
+	state    DSPHealthState
+	lastErr  string
+}
+
+func (e *Engine) dspSpans(now time.Time) []dspSpan {
+	ents := e.ReadDSPTimeline(dspTimelineMaxLines)
+	var spans []dspSpan
+	for _, ent := range ents {
+		t, err := time.Parse(time.RFC3339, ent.Time)
+		if err != nil {
+			continue
+		}
+		if n := len(spans); n > 0 {
+			spans[n-1].to = t
+		}
+		spans = append(spans, dspSpan{from: t, state: ent.State, lastErr: ent.LastError})
+	}
+	if n := len(spans); n > 0 {
+		spans[n-1].to = now
+	}
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].from.Before(spans[j].from) })
+	return spans
+}
+
+func dspDown(s DSPHealthState) bool {
+	return s == DSPHealthDegraded || s == DSPHealthDisconnected
+}
+
+// DSPOutageReport builds the report for the last `days` UTC days and `weeks`
+// weeks; the outage list covers whichever is longer (newest first, at most
+// maxOutages).
+func (e *Engine) DSPOutageReport(days, weeks, maxOutages int) DSPOutageReport {
+	now := time.Now().UTC()
+	today := now.Truncate(24 * time.Hour)
+	dayFrom := today.AddDate(0, 0, -(days - 1))
+	monday := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+	weekFrom := monday.AddDate(0, 0, -7*(weeks-1))
+	from := dayFrom
+	if weekFrom.Before(from) {
+		from = weekFrom
+	}
+
+	mkPeriods := func(start time.Time, n int, step func(time.Time) time.Time) ([]DSPAvailability, []time.Time) {
+		out := make([]DSPAvailability, n)
+		bounds := make([]time.Time, n+1)
+		t := start
+		for i := 0; i < n; i++ {
+			out[i].Start = t.Format(time.RFC3339)
+			bounds[i] = t
+			t = step(t)
+		}
+		bounds[n] = t
+		return out, bounds
+	}
+	daily, dayB := mkPeriods(dayFrom, days, func(t time.Time) time.Time { return t.AddDate(0, 0, 1) })
+	weekly, weekB := mkPeriods(weekFrom, weeks, func(t time.Time) time.Time { return t.AddDate(0, 0, 7) })
+	var total DSPAvailability
+
+	account := func(ps []DSPAvailability, bounds []time.Time, sp dspSpan) {
+		for i := range ps {
+			a, b := bounds[i], bounds[i+1]
+			if sp.from.After(a) {
+				a = sp.from
+			}
+			if sp.to.Before(b) {
+				b = sp.to
+			}
+			if !b.After(a) {
+				continue
+			}
+			addSpanTime(&ps[i], sp.state, b.Sub(a).Seconds())
+		}
+	}
+	countStart := func(ps []DSPAvailability, bounds []time.Time, t time.Time) {
+		for i := range ps {
+			if !t.Before(bounds[i]) && t.Before(bounds[i+1]) {
+				ps[i].Outages++
+			}
+		}
+	}
+
+	var outages []DSPOutage
+	var cur *DSPOutage
+	var curStart time.Time
+	curCounted := false // cur is in total.Outages
+	for _, sp := range e.dspSpans(now) {
+		if sp.to.After(from) {
+			clipped := sp
+			if clipped.from.Before(from) {
+				clipped.from = from
+			}
+			account(daily, dayB, clipped)
+			account(weekly, weekB, clipped)
+			addSpanTime(&total, clipped.state, clipped.to.Sub(clipped.from).Seconds())
+		}
+		switch {
+		case dspDown(sp.state) && cur == nil:
+			cur = &DSPOutage{Start: sp.from.Format(time.RFC3339), WorstState: sp.state}
+			curStart = sp.from
+			countStart(daily, dayB, sp.from)
+			countStart(weekly, weekB, sp.from)
+			curCounted = false
+		case !dspDown(sp.state) && cur != nil:
+			cur.End = sp.from.Format(time.RFC3339)
+			cur.DurationS = roundS(sp.from.Sub(curStart).Seconds())
+			if sp.from.After(from) {
+				outages = append(outages, *cur)
+			}
+			cur = nil
+		}
+		if cur != nil && !curCounted && sp.to.After(from) {
+			total.Outages++
+			curCounted = true
+		}
+		if cur != nil && dspDown(sp.state) {
+			if sp.state == DSPHealthDisconnected {
+				cur.WorstState = DSPHealthDisconnected
+			}
+			if sp.lastErr != "" {
+				cur.LastError = sp.lastErr
+			}
+		}
+	}
+	if cur != nil {
+		cur.Ongoing = true
+		cur.DurationS = roundS(now.Sub(curStart).Seconds())
+		outages = append(outages, *cur)
+	}
+
+	// Newest first, like the other history endpoints.
+	for i, j := 0, len(outages)-1; i < j; i, j = i+1, j-1 {
+		outages[i], outages[j] = outages[j], outages[i]
+	}
+	if len(outages) > maxOutages {
+		outages = outages[:maxOutages]
+	}
+	if outages == nil {
+		outages = []DSPOutage{}
+	}
+
+	for i := range daily {
+		finishAvailability(&daily[i])
+	}
+	for i := range weekly {
+		finishAvailability(&weekly[i])
+	}
+	finishAvailability(&total)
+
+	rep := DSPOutageReport{
+		Ok:              true,
+		From:            from.Format(time.RFC3339),
+		To:              now.Format(time.RFC3339),
+		Outages:         outages,
+		Daily:           daily,
+		Weekly:          weekly,
+		AvailabilityPct: total.AvailabilityPct,
+	}
+	if total.Outages > 0 {
+		mtbf := roundS(total.OkS / float64(total.Outages))
+		mttr := roundS((total.DegradedS + total.DisconnectedS) / float64(total.Outages))
+		rep.MTBFS, rep.MTTRS = &mtbf, &mttr
+	}
+	return rep
+}
+
+func addSpanTime(a *DSPAvailability, s DSPHealthState, secs float64) {
+	switch s {
+	case DSPHealthOK:
+		a.OkS += secs
+	case DSPHealthDegraded:
+		a.DegradedS += secs
+	case DSPHealthDisconnected:
+		a.DisconnectedS += secs
+	default:
+		return
+	}
+	a.ObservedS += secs
+}
+
+func finishAvailability(a *DSPAvailability) {
+	a.ObservedS, a.OkS = roundS(a.ObservedS), roundS(a.OkS)
+	a.DegradedS, a.DisconnectedS = roundS(a.DegradedS), roundS(a.DisconnectedS)
+	if a.ObservedS > 0 {
+		pct := math.Round(a.OkS/a.ObservedS*100000) / 1000
+		a.AvailabilityPct = &pct
+	}
+}
+
+func roundS(v float64) float64 { return math.Round(v*10) / 10 }
@@ -0,0 +1,199 @@
+package app
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// DSP reconnect (v0.3.73)
+//
+// POST /api/reconnect used to only log "reconnect requested". It now runs a
+// real, bounded sequence and reports each step:
+//
+//  1. teardown   drop cached Core state (active Core, per-Core status). ECP
+//     connections are per command and there are no change-group
+//     subscriptions, so nothing long-lived needs closing.
+//  2. reset      clear the diagnostics gathered before the outage: last
+//     error, state reason and the latency windows (dsp_latency.go), so a
+//     stale p95 cannot keep the fresh link DEGRADED. The state and the
+//     failure/success counters are kept: only the probe below moves them,
+//     through the normal state machine (recordDSPProbeLocked), so a Core
+//     that is still down stays DISCONNECTED and a recovering link still
+//     needs recover_after successes.
+//  3. probe      one immediate health probe (same as "Test DSP Now").
+//  4. rehydrate  LIVE only: read every allowlisted named control with ECP
+//     "cg" from the active Core and adopt its position, so the UI shows
+//     what the Core really has after an outage.
+//
+// The endpoint needs the operator role or higher. Each step is journaled as
+// "dsp.reconnect" (see journal.go) so the sequence
+// shows up in the event timeline. Concurrent calls are serialized.
+// ---------------------------------------------------------------------------
+
+// ReconnectStep is one step of the reconnect sequence.
+type ReconnectStep struct {
+	Step       string  `json:"step"`
+	Ok         bool    `json:"ok"`
+	Skipped    bool    `json:"skipped,omitempty"`
+	Detail     string  `json:"detail,omitempty"`
+	DurationMs float64 `json:"durationMs"`
+}
+
+// ReconnectResult is returned by POST /api/reconnect.
+type ReconnectResult struct {
+	Ok        bool              `json:"ok"`
+	Mode      string            `json:"mode"`
+	StartedAt string            `json:"startedAt"`
+	Steps     []ReconnectStep   `json:"steps"`
+	Health    DSPHealthSnapshot `json:"health"`
+	// Rehydrated lists controls whose value changed, keyed by RC id.
+	Rehydrated map[string]float64 `json:"rehydrated,omitempty"`
+}
+
+// Reconnect runs the reconnect sequence.
+func (e *Engine) Reconnect() ReconnectResult {
+	e.reconnectMu.Lock()
+	defer e.reconnectMu.Unlock()
+
+	cfg := e.GetConfigCopy()
+	mode := strings.ToLower(strings.TrimSpace(cfg.DSP.Mode))
+	res := ReconnectResult{Ok: true, Mode: mode, StartedAt: time.Now().UTC().Format(time.RFC3339)}
+	log.Printf("reconnect requested (mode=%s)", mode)
+	e.RecordEvent("dsp.reconnect", SeverityInfo, map[string]any{"state": "started", "mode": mode})
+
+	run := func(name string, fn func() (ok, skipped bool, detail string)) {
+		start := time.Now()
+		ok, skipped, detail := fn()
+		st := ReconnectStep{
+			Step:       name,
+			Ok:         ok,
+			Skipped:    skipped,
+			Detail:     detail,
+			DurationMs: math.Round(float64(time.Since(start))/float64(time.Millisecond)*10) / 10,
+		}
+		res.Steps = append(res.Steps, st)
+		if !ok {
+			res.Ok = false
+		}
+		sev := SeverityInfo
+		if !ok {
+			sev = SeverityWarn
+		}
+		e.RecordEvent("dsp.reconnect", sev, map[string]any{"step": name, "ok": ok, "skipped": skipped, "detail": detail})
+	}
+
+	e.ensureDSPHealthInit()
+	run("teardown", func() (bool, bool, string) {
+		e.dspMu.Lock()
+		prev := ""
+		if e.dsp.activeCore != nil {
+			prev = e.dsp.activeCore.label()
+		}
+		e.dsp.activeCore = nil
+		e.dsp.cores = nil
+		e.dspMu.Unlock()
+		detail := "no persistent ECP sessions or subscriptions held"
+		if prev != "" {
+			detail += "; forgot active core " + prev
+		}
+		return true, false, detail
+	})
+
+	run("reset", func() (bool, bool, string) {
+		e.dspMu.Lock()
+		samples := 0
+		for _, w := range e.dsp.latency {
+			samples += len(w.samples)
+		}
+		e.dsp.lastErr = ""
+		e.dsp.reason = ""
+		e.dsp.latency = nil
+		state, failures := e.dsp.state, e.dsp.failures
+		e.dspMu.Unlock()
+		return true, false, fmt.Sprintf("cleared last error, reason and %d latency samples; state kept at %s (%d failures) for the probe", samples, state, failures)
+	})
+
+	run("probe", func() (bool, bool, string) {
+		snap := e.TestDSPConnectivity(0)
+		if !snap.Connected {
+			return false, false, fmt.Sprintf("%s: %s", snap.State, snap.LastError)
+		}
+		detail := string(snap.State)
+		if snap.ActiveCore != "" {
+			detail += " via " + snap.ActiveCore
+		}
+		return true, false, detail
+	})
+
+	run("rehydrate", func() (bool, bool, string) {
+		if mode != "live" {
+			return true, true, mode + " mode: control values are engine-owned"
+		}
+		if !res.Steps[len(res.Steps)-1].Ok {
+			return false, true, "skipped: probe failed"
+		}
+		changed, read, errs := e.rehydrateControls(cfg)
+		res.Rehydrated = changed
+		detail := fmt.Sprintf("%d controls read, %d changed", read, len(changed))
+		if len(errs) > 0 {
+			detail += fmt.Sprintf(", %d failed (%s)", len(errs), strings.Join(errs, "; "))
+		}
+		return len(errs) == 0, false, detail
+	})
+
+	res.Health = e.DSPHealthSnapshot()
+	sev := SeverityInfo
+	if !res.Ok {
+		sev = SeverityWarn
+	}
+	e.RecordEvent("dsp.reconnect", sev, map[string]any{"state": "done", "ok": res.Ok})
+	return res
+}
+
+// rehydrateControls reads each allowlisted, named, non-meter control from the
+// Core and adopts its position: e.rc is kept in position units (0..1), the
+// same unit dspWriteControl sends. Names come from ecpControlName, as on the
+// write path. Network calls happen without e.mu held.
+func (e *Engine) rehydrateControls(cfg Config) (changed map[string]float64, read int, errs []string) {
+	type ctl struct {
+		id   int
+		name string
+	}
+	var ctls []ctl
+	for _, id := range cfg.RCAllowlist {
+		name := cfg.ecpControlName(id)
+		if isMeterRC(id) || name == "" {
+			continue
+		}
+		ctls = append(ctls, ctl{id, name})
+	}
+	sort.Slice(ctls, func(i, j int) bool { return ctls[i].id < ctls[j].id })
+
+	timeout := time.Duration(cfg.DSP.Health.TimeoutMs) * time.Millisecond
+	got := map[int]float64{}
+	for _, c := range ctls {
+		_, pos, err := e.ecpGetControl(c.name, timeout)
+		if err != nil {
+			errs = append(errs, c.name+": "+err.Error())
+			continue
+		}
+		read++
+		got[c.id] = pos
+	}
+
+	changed = map[string]float64{}
+	e.mu.Lock()
+	for id, v := range got {
+		if e.rc[id] != v {
+			e.rc[id] = v
+			changed[itoa(id)] = v
+		}
+	}
+	e.mu.Unlock()
+	return changed, read, errs
+}
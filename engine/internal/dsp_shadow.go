@@ -0,0 +1,85 @@
+package app
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Shadow ("dry-run live") mode (v0.3.78)
+//
+// Before trusting LIVE writes on air we want to see exactly what the engine
+// would send. With dsp.mode=shadow every write to a live-flagged control
+// (dsp_canary.go) is translated into the exact ECP command line, target Core
+// and expected response, and logged instead of sent:
+//
+//	{"action":"dsp.write","details":{"dryRun":true,
+//	  "command":"csv STUB_SPK_MUTE 1","target":"10.0.0.5:1702",
+//	  "expect":"cv \"STUB_SPK_MUTE\" * 1 *", ...}}
+//
+// The record lands in state/intents.jsonl and in the Engineering "last
+// write" panel. The RC cache updates as in mock mode. Nothing is written to
+// the Core: no arm is needed and ecpSendSet is never called. Health probes
+// still run as in LIVE mode so the link can be watched during the rehearsal.
+// ---------------------------------------------------------------------------
+
+// DSPModeShadow is the dsp.mode value for shadow (dry-run live) mode.
+const DSPModeShadow = "shadow"
+
+// dspModeWrites reports whether mode routes control writes through
+// dspWriteControl (live, or shadow as a dry run).
+func dspModeWrites(mode string) bool {
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	return mode == "live" || mode == DSPModeShadow
+}
+
+// ecpExpectedCV is the response a Core sends for a successful csv / csp;
+// fields that depend on the control are left as "*".
+func ecpExpectedCV(controlName string, val float64, cmd string) string {
+	if strings.HasPrefix(cmd, "csp ") {
+		return fmt.Sprintf(`cv "%s" * * %v`, controlName, val)
+	}
+	return fmt.Sprintf(`cv "%s" * %v *`, controlName, val)
+}
+
+// dspShadowWrite logs the ECP write for a live control without sending it.
+func (e *Engine) dspShadowWrite(cfg Config, id int, name string, val float64, source string) error {
+	cmd := cfg.ecpSetCommand(id, name, val)
+	target := e.activeDSPEndpoint(cfg).addr()
+	expect := ecpExpectedCV(name, val, cmd)
+	wev := IntentEvent{
+		TS:     time.Now().UTC().Format(time.RFC3339),
+		Action: "dsp.write",
+		Source: source,
+		Mode:   DSPModeShadow,
+		Details: map[string]any{
+			"rc":      id,
+			"name":    name,
+			"value":   val,
+			"ok":      true,
+			"dryRun":  true,
+			"command": cmd,
+			"target":  target,
+			"expect":  expect,
+		},
+	}
+	if err := e.appendIntent(wev); err != nil {
+		return fmt.Errorf("dsp write log failed: %w", err)
+	}
+	e.setLastDSPWrite(&DSPWriteStatus{
+		TS:      wev.TS,
+		Name:    name,
+		RC:      id,
+		Value:   val,
+		Ok:      true,
+		Mode:    DSPModeShadow,
+		DryRun:  true,
+		Command: cmd,
+		Target:  target,
+		Expect:  expect,
+	})
+	log.Printf("dsp write DRY RUN: %s -> %s", cmd, target)
+	return nil
+}
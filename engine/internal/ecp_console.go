@@ -0,0 +1,279 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Admin ECP console (v0.3.80)
+//
+// Engineers used to diagnose the Core with netcat from the VM. The console
+// sends single ECP commands through the engine's own DSP session (active
+// Core, timeouts, transcript) and returns the parsed reply:
+//
+//	POST /api/dsp/console {"command": "cg STUB_SPK_MUTE"}
+//
+// Read-only commands are allowed as-is:
+//
+//	sg            Core status ("sr" reply)
+//	cg <name>     read a Named Control ("cv" reply)
+//
+// Write commands (csv / csp <name> <value>) need an elevated
+// confirmation: "confirm" must repeat the exact command. They are held to
+// the same rules as POST /api/rc: the name must be the Named Control of an
+// allowlisted, live-flagged RC, the verb must be the one the engine uses for
+// it (csv for booleans, csp with the 0..1 position otherwise; see
+// ecpSetCommand), LIVE writes must be armed (dsp_arming.go), the show lock
+// and the control policy must allow the value. A successful write updates
+// the RC cache with the position the Core reports. Anything else (login,
+// change groups, multi-line input) is refused; a login PIN is redacted
+// before it is echoed or audited (see redactECP).
+//
+// Mock mode never contacts the Core, so the console is refused there. The
+// endpoint is admin-only. Every command, including refused ones, is
+// audited in state/intents.jsonl as "dsp.console".
+// ---------------------------------------------------------------------------
+
+// ECPConsoleRequest is the body of POST /api/dsp/console.
+type ECPConsoleRequest struct {
+	Command string `json:"command"`
+	// Confirm must equal Command for write commands.
+	Confirm string `json:"confirm,omitempty"`
+	Source  string `json:"source,omitempty"`
+}
+
+// ECPReply is a parsed ECP response line.
+type ECPReply struct {
+	Kind     string   `json:"kind"` // "cv", "sr" or the error token (e.g. "bad_id")
+	Name     string   `json:"name,omitempty"`
+	String   string   `json:"string,omitempty"`
+	Value    *float64 `json:"value,omitempty"`
+	Position *float64 `json:"position,omitempty"`
+	Design   string   `json:"design,omitempty"`
+	Primary  *bool    `json:"primary,omitempty"`
+	Active   *bool    `json:"active,omitempty"`
+	Error    bool     `json:"error,omitempty"`
+}
+
+// ECPConsoleResult is one console exchange.
+type ECPConsoleResult struct {
+	Command string    `json:"command"`
+	Write   bool      `json:"write"`
+	Target  string    `json:"target,omitempty"`
+	Reply   string    `json:"reply,omitempty"`
+	Parsed  *ECPReply `json:"parsed,omitempty"`
+	TookMs  int64     `json:"tookMs"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// ECPConsoleError is a console command refused before anything was sent.
+// Rule is "command", "mode", "confirm", "control" or "armed".
+type ECPConsoleError struct {
+	Rule   string
+	Detail string
+}
+
+func (err *ECPConsoleError) Error() string {
+	return "ecp console: " + err.Detail
+}
+
+// consoleWriteTarget resolves a console write (verb, Named Control, value) to
+// the allowlisted RC it changes and the value in RC (position) units.
+func consoleWriteTarget(cfg Config, f []string) (int, float64, error) {
+	verb, name := strings.ToLower(f[0]), f[1]
+	id := 0
+	for _, rc := range cfg.RCAllowlist {
+		if !isMeterRC(rc) && cfg.ecpControlName(rc) == name {
+			id = rc
+			break
+		}
+	}
+	if id == 0 {
+		return 0, 0, &ECPConsoleError{Rule: "control", Detail: fmt.Sprintf("%q is not the Named Control of an allowlisted control", name)}
+	}
+	if !cfg.controlLive(id) {
+		return 0, 0, &ECPConsoleError{Rule: "control", Detail: fmt.Sprintf("%s (rc %d) is not live (control_policies live: true)", name, id)}
+	}
+	if len(f) != 3 {
+		return 0, 0, &ECPConsoleError{Rule: "command", Detail: "write commands take exactly one value"}
+	}
+	val, err := strconv.ParseFloat(f[2], 64)
+	if err != nil {
+		return 0, 0, &ECPConsoleError{Rule: "command", Detail: fmt.Sprintf("value %q is not a number", f[2])}
+	}
+	if want, _, _ := strings.Cut(cfg.ecpSetCommand(id, name, val), " "); verb != want {
+		return 0, 0, &ECPConsoleError{Rule: "command", Detail: fmt.Sprintf("%s is written with %s (the engine keeps positions)", name, want)}
+	}
+	return id, val, nil
+}
+
+var ecpConsoleWrites = map[string]bool{"csv": true, "csp": true}
+
+// parseConsoleCommand validates cmd against the allowlist and returns its
+// fields and whether it writes.
+func parseConsoleCommand(cmd string) ([]string, bool, error) {
+	if strings.ContainsAny(cmd, "\r\n") {
+		return nil, false, &ECPConsoleError{Rule: "command", Detail: "one command per request"}
+	}
+	f := strings.Fields(cmd)
+	if len(f) == 0 {
+		return nil, false, &ECPConsoleError{Rule: "command", Detail: "a command is required"}
+	}
+	switch verb := strings.ToLower(f[0]); {
+	case verb == "sg" && len(f) == 1:
+		return f, false, nil
+	case verb == "cg" && len(f) == 2:
+		return f, false, nil
+	case ecpConsoleWrites[verb] && len(f) >= 3:
+		return f, true, nil
+	default:
+		return nil, false, &ECPConsoleError{Rule: "command", Detail: fmt.Sprintf("%q is not allowed (sg, cg NAME; csv/csp NAME VALUE with confirmation)", redactECP(cmd))}
+	}
+}
+
+// parseECPReply splits a cv / sr / error reply into its fields.
+func parseECPReply(line string) *ECPReply {
+	verb, rest, _ := strings.Cut(strings.TrimSpace(line), " ")
+	r := &ECPReply{Kind: verb}
+	var quoted []string
+	rest = strings.TrimSpace(rest)
+	for strings.HasPrefix(rest, `"`) {
+		q, err := strconv.QuotedPrefix(rest)
+		if err != nil {
+			break
+		}
+		s, _ := strconv.Unquote(q)
+		quoted = append(quoted, s)
+		rest = strings.TrimSpace(rest[len(q):])
+	}
+	nums := strings.Fields(rest)
+	switch verb {
+	case "cv":
+		if len(quoted) > 0 {
+			r.Name = quoted[0]
+		}
+		if len(quoted) > 1 {
+			r.String = quoted[1]
+		}
+		if len(nums) > 0 {
+			if v, err := strconv.ParseFloat(nums[0], 64); err == nil {
+				r.Value = &v
+			}
+		}
+		if len(nums) > 1 {
+			if p, err := strconv.ParseFloat(nums[1], 64); err == nil {
+				r.Position = &p
+			}
+		}
+	case "sr":
+		if len(quoted) > 0 {
+			r.Design = quoted[0]
+		}
+		if len(nums) >= 2 {
+			primary, active := nums[0] == "1", nums[1] == "1"
+			r.Primary, r.Active = &primary, &active
+		}
+	default:
+		r.Error = true
+		if len(quoted) > 0 {
+			r.Name = quoted[0]
+		}
+	}
+	return r
+}
+
+// ECPConsole runs one allowlisted command and audits it. Refusals return an
+// *ECPConsoleError or *ShowLockError; transport errors are reported in the
+// result (with a nil error) so the console shows them.
+func (e *Engine) ECPConsole(req ECPConsoleRequest, who Identity) (ECPConsoleResult, error) {
+	cmd := strings.TrimSpace(req.Command)
+	res := ECPConsoleResult{Command: redactECP(cmd)}
+	source := strings.TrimSpace(req.Source)
+	if source == "" {
+		source = "api"
+	}
+	audit := func(refused error) {
+		details := map[string]any{
+			"command": redactECP(cmd),
+			"write":   res.Write,
+			"caller":  who.Name,
+			"ok":      refused == nil && res.Error == "",
+		}
+		if res.Target != "" {
+			details["target"] = res.Target
+			details["reply"] = res.Reply
+		}
+		if refused != nil {
+			details["rejected"] = true
+			details["error"] = refused.Error()
+		} else if res.Error != "" {
+			details["error"] = res.Error
+		}
+		_ = e.appendIntent(IntentEvent{Action: "dsp.console", Source: source, Details: details})
+	}
+
+	cfg := e.GetConfigCopy()
+	f, write, err := parseConsoleCommand(cmd)
+	res.Write = write
+	if err == nil && !dspModeWrites(cfg.DSP.Mode) {
+		err = &ECPConsoleError{Rule: "mode", Detail: "dsp.mode is mock; the console needs live or shadow"}
+	}
+	var rc int
+	var val float64
+	if err == nil && write {
+		if strings.TrimSpace(req.Confirm) != cmd {
+			err = &ECPConsoleError{Rule: "confirm", Detail: "write commands need confirm set to the exact command"}
+		} else {
+			rc, val, err = consoleWriteTarget(cfg, f)
+		}
+		switch {
+		case err != nil:
+		case !e.DSPLiveActive():
+			err = &ECPConsoleError{Rule: "armed", Detail: "LIVE writes are not armed (admin: arm via POST /api/dsp/arm)"}
+		default:
+			if lockErr := e.CheckShowLock(f[1], who); lockErr != nil {
+				err = lockErr
+			} else if pErr := e.controlPolicyCheck("dsp.console", rc, val, who, source); pErr != nil {
+				err = pErr
+			}
+		}
+	}
+	if err != nil {
+		audit(err)
+		return res, err
+	}
+
+	res.Target = e.activeDSPEndpoint(cfg).addr()
+	start := time.Now()
+	var line string
+	var xerr error
+	if write {
+		// Serialized with the other live writes, like dspWriteControl.
+		e.dspWriteMu.Lock()
+		line, xerr = e.ecpSendSet(cmd, 1500*time.Millisecond)
+		e.dspWriteMu.Unlock()
+	} else {
+		line, xerr = e.ecpExchange(cmd, 1500*time.Millisecond)
+	}
+	res.TookMs = time.Since(start).Milliseconds()
+	res.Reply = line
+	if line != "" {
+		res.Parsed = parseECPReply(line)
+	}
+	if xerr != nil {
+		res.Error = xerr.Error()
+	} else if res.Parsed != nil && res.Parsed.Error {
+		res.Error = "ecp error: " + line
+	} else if write {
+		// Keep the RC cache in step with the Core, in position units.
+		if res.Parsed != nil && res.Parsed.Kind == "cv" && res.Parsed.Name == f[1] && res.Parsed.Position != nil {
+			val = *res.Parsed.Position
+		}
+		e.storeRC(rc, val)
+	}
+	audit(nil)
+	return res, nil
+}
@@ -0,0 +1,215 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Raw ECP transcript capture (v0.3.79)
+//
+// When a write fails, DSPWriteStatus.Resp holds only the last response line.
+// QSC support wants the exact wire exchange, so every DSP connection (health
+// probes, reads, writes) now goes through dialECP, which can record:
+//
+//	{seq, ts, conn, dir, target, bytes, data}
+//
+// dir is "open", "tx", "rx", "close" or "error"; conn numbers connections
+// since engine start. Capture is off by default and bounded to
+// ecpTranscriptMax entries (oldest dropped). Admin API:
+//
+//	GET  /api/dsp/transcript                        status + entries (JSON)
+//	GET  /api/dsp/transcript?entries=0              status only
+//	GET  /api/dsp/transcript?format=text&download=1 export for a ticket
+//	POST /api/dsp/transcript {"enabled": true, "clear": false}
+//
+// Credentials are redacted before they are stored: the PIN of an ECP
+// "login <name> <pin>" line is replaced with "****". The API needs the admin
+// role (session, token or PIN); toggles are audited in state/intents.jsonl
+// as "dsp.transcript" with the caller's name and role.
+// ---------------------------------------------------------------------------
+
+const ecpTranscriptMax = 5000
+
+// ECPTranscriptEntry is one recorded wire event.
+type ECPTranscriptEntry struct {
+	Seq    int64  `json:"seq"`
+	TS     string `json:"ts"`
+	Conn   int64  `json:"conn"`
+	Dir    string `json:"dir"`
+	Target string `json:"target,omitempty"`
+	Bytes  int    `json:"bytes"`
+	Data   string `json:"data,omitempty"`
+}
+
+// ECPTranscriptStatus is the recorder state returned by the API.
+type ECPTranscriptStatus struct {
+	Enabled   bool                 `json:"enabled"`
+	Max       int                  `json:"max"`
+	Count     int                  `json:"count"`
+	Dropped   int64                `json:"dropped"`
+	StartedAt string               `json:"startedAt,omitempty"`
+	Entries   []ECPTranscriptEntry `json:"entries,omitempty"`
+}
+
+// ecpTranscript is stored on Engine with its own mutex.
+type ecpTranscript struct {
+	mu        sync.Mutex
+	enabled   bool
+	startedAt time.Time
+	seq       int64
+	conns     int64
+	dropped   int64
+	entries   []ECPTranscriptEntry
+}
+
+func (t *ecpTranscript) nextConn() int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.conns++
+	return t.conns
+}
+
+func (t *ecpTranscript) record(conn int64, dir, target string, b []byte) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.enabled {
+		return
+	}
+	t.seq++
+	t.entries = append(t.entries, ECPTranscriptEntry{
+		Seq:    t.seq,
+		TS:     time.Now().UTC().Format("2006-01-02T15:04:05.000Z07:00"),
+		Conn:   conn,
+		Dir:    dir,
+		Target: target,
+		Bytes:  len(b),
+		Data:   redactECP(string(b)),
+	})
+	if n := len(t.entries) - ecpTranscriptMax; n > 0 {
+		t.entries = append([]ECPTranscriptEntry(nil), t.entries[n:]...)
+		t.dropped += int64(n)
+	}
+}
+
+// redactECP masks the PIN of ECP "login <name> <pin>" lines.
+func redactECP(s string) string {
+	if !strings.Contains(strings.ToLower(s), "login") {
+		return s
+	}
+	lines := strings.SplitAfter(s, "\n")
+	for i, ln := range lines {
+		f := strings.Fields(ln)
+		if len(f) >= 3 && strings.EqualFold(f[0], "login") {
+			end := ln[len(strings.TrimRight(ln, "\r\n")):]
+			lines[i] = f[0] + " " + f[1] + " ****" + end
+		}
+	}
+	return strings.Join(lines, "")
+}
+
+// ecpConn records traffic on one DSP connection.
+type ecpConn struct {
+	net.Conn
+	t      *ecpTranscript
+	id     int64
+	target string
+	once   sync.Once
+}
+
+func (c *ecpConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	c.t.record(c.id, "tx", c.target, b[:n])
+	if err != nil {
+		c.t.record(c.id, "error", c.target, []byte(err.Error()))
+	}
+	return n, err
+}
+
+func (c *ecpConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if n > 0 {
+		c.t.record(c.id, "rx", c.target, b[:n])
+	}
+	if err != nil && err != io.EOF {
+		c.t.record(c.id, "error", c.target, []byte(err.Error()))
+	}
+	return n, err
+}
+
+func (c *ecpConn) Close() error {
+	c.once.Do(func() { c.t.record(c.id, "close", c.target, nil) })
+	return c.Conn.Close()
+}
+
+// dialECP opens a TCP connection to a Core; all DSP traffic uses it so the
+// transcript sees every exchange.
+func (e *Engine) dialECP(addr string, timeout time.Duration) (net.Conn, error) {
+	t := e.transcript
+	id := t.nextConn()
+	c, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		t.record(id, "error", addr, []byte(err.Error()))
+		return nil, err
+	}
+	t.record(id, "open", addr, nil)
+	return &ecpConn{Conn: c, t: t, id: id, target: addr}, nil
+}
+
+// ECPTranscript returns the recorder state, with entries when requested.
+func (e *Engine) ECPTranscript(withEntries bool) ECPTranscriptStatus {
+	t := e.transcript
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	st := ECPTranscriptStatus{Enabled: t.enabled, Max: ecpTranscriptMax, Count: len(t.entries), Dropped: t.dropped}
+	if !t.startedAt.IsZero() {
+		st.StartedAt = t.startedAt.UTC().Format(time.RFC3339)
+	}
+	if withEntries {
+		st.Entries = append([]ECPTranscriptEntry{}, t.entries...)
+	}
+	return st
+}
+
+// SetECPTranscript turns capture on or off for who, optionally clearing it
+// first.
+func (e *Engine) SetECPTranscript(enabled, clear bool, who Identity) ECPTranscriptStatus {
+	t := e.transcript
+	t.mu.Lock()
+	if clear {
+		t.entries = nil
+		t.dropped = 0
+	}
+	if enabled && !t.enabled {
+		t.startedAt = time.Now()
+	}
+	t.enabled = enabled
+	t.mu.Unlock()
+
+	_ = e.appendIntent(IntentEvent{
+		Action:  "dsp.transcript",
+		Source:  who.Name,
+		Details: map[string]any{"enabled": enabled, "clear": clear, "caller": who.Name, "role": who.Role},
+	})
+	return e.ECPTranscript(false)
+}
+
+// WriteECPTranscriptText writes entries one per line for support tickets:
+//
+//	2026-01-02T15:04:05.123Z  conn=3  tx     10.0.0.5:1702  3B  "sg\n"
+func WriteECPTranscriptText(w io.Writer, st ECPTranscriptStatus) {
+	fmt.Fprintf(w, "# StudioB ECP transcript: %d entries, %d dropped, capture %s\n",
+		st.Count, st.Dropped, map[bool]string{true: "on", false: "off"}[st.Enabled])
+	for _, en := range st.Entries {
+		fmt.Fprintf(w, "%s  conn=%d  %-5s  %s  %dB", en.TS, en.Conn, en.Dir, en.Target, en.Bytes)
+		if en.Data != "" {
+			fmt.Fprintf(w, "  %s", strconv.Quote(en.Data))
+		}
+		fmt.Fprintln(w)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -72,6 +73,14 @@ type Engine struct {
 	// explicitly clicks "Enter LIVE Mode" (admin-gated).
 	dspLiveArmed   bool
 	dspLiveArmedAt time.Time
+	// v0.3.74: arming is time-limited and tied to the DSP config it was
+	// granted for (see dsp_arming.go). Guarded by dspMu.
+	dspLiveArmUntil  time.Time
+	dspLiveArmReason string
+	dspLiveArmBy     string
+	dspLiveArmRole   string
+	dspLiveArmSig    string
+	dspLiveArmTimer  *time.Timer
 	// cfgPath is the canonical on-disk config path the engine was started with.
 	// v0.2.77: used for hot-reload when Engineering saves config.
 	cfgPath string
@@ -102,17 +111,55 @@ type Engine struct {
 	dspMonOn   bool
 	// Base state directory (written by installer). Used for small, append-only state files.
 	stateDir string
+	// v0.3.72: studio name; "" is the default studio (see studios.go).
+	studio string
 	cfg      *Config
 	version  string
 
 	mu       sync.RWMutex
+	// rc holds control values in ECP position units (0..1; 0/1 for
+	// booleans). Reads from the Core (rehydrate) and writes to it
+	// (dspWriteControl) use the same unit.
 	rc       map[int]float64
 	lastSent map[int]float64
+	// v0.3.60: meter ballistics state (raw source value, level, held peak).
+	// Guarded by mu. Processed levels are mirrored into rc for publishing.
+	meters       map[int]*meterChannel
+	lastSentPeak map[int]float64
+	// v0.3.62: audio alarm state keyed "kind:group" (see alarms.go). Guarded by mu.
+	alarms map[string]*alarmState
+	// v0.3.64: meter trend recorder (own mutex; see meter_history.go).
+	history *meterHistory
+	// v0.3.70: runtime event journal (own mutex; see journal.go).
+	journal *eventJournal
+	// v0.3.65: running mock scenario, nil when none. Guarded by mu.
+	scenario *scenarioRun
+	// v0.3.66: mock console signal model (see mock_mixer.go). Guarded by mu.
+	mockMixer *mockMixer
+	// v0.3.73: serializes Reconnect (see dsp_reconnect.go).
+	reconnectMu sync.Mutex
+	// v0.3.75: on-air write freeze (see show_lock.go).
+	showLockMu sync.Mutex
+	showLock   ShowLock
+	// v0.3.79: raw ECP transcript (own mutex; see ecp_transcript.go).
+	transcript *ecpTranscript
+	// v0.3.81: last design conformance report (see dsp_conformance.go).
+	conformance *dspConformance
+	// dspWriteMu serializes live control writes with the conformance write
+	// probe, so the probe never reverts an engine write.
+	dspWriteMu sync.Mutex
 
 	upgrader websocket.Upgrader
 
 	clientsMu sync.Mutex
-	clients   map[*websocket.Conn]bool
+	clients   map[*websocket.Conn]*wsClient
+
+	// v0.3.58: sequence-numbered fan-out shared by /ws topics and /api/stream (SSE).
+	stream *streamHub
+
+	// v0.3.59: cookie sessions (in-memory; see auth.go).
+	sessionsMu sync.Mutex
+	sessions   map[string]Session
 
 	updateMu      sync.Mutex
 	updateCached  *UpdateInfo
@@ -190,33 +237,75 @@ type StudioStatus struct {
 		Mute     bool    `json:"mute"`
 		AutoMute bool    `json:"automute"`
 	} `json:"speaker"`
-	Meters struct {
-		SpkL float64 `json:"spkL"`
-		SpkR float64 `json:"spkR"`
-		PgmL float64 `json:"pgmL"`
-		PgmR float64 `json:"pgmR"`
-		RsrL float64 `json:"rsrL"`
-		RsrR float64 `json:"rsrR"`
-	} `json:"meters"`
+	Meters StudioMeters `json:"meters"`
+	// Peaks are the held peak values (v0.3.60), same shape as Meters.
+	Peaks StudioMeters `json:"peaks"`
+	// MetersDB / PeaksDB are the same values calibrated to dBFS (v0.3.61,
+	// see meters.calibration).
+	MetersDB StudioMeters `json:"metersDb"`
+	PeaksDB  StudioMeters `json:"peaksDb"`
+	// FloorsDB is each meter's calibration floor (dBFS): the bottom of its
+	// scale, so the UI can place the bar and the -12/0 marks in dB.
+	FloorsDB StudioMeters `json:"floorsDb"`
+	// Alarms lists active audio alarms (v0.3.62); empty when all is well.
+	Alarms []AlarmStatus `json:"alarms"`
+	// ShowLock is set while a show lock is active (v0.3.75).
+	ShowLock *ShowLock `json:"showLock,omitempty"`
+}
+
+// StudioMeters is one value per Studio meter. The unit depends on the field
+// it fills in StudioStatus: Meters / Peaks are normalized 0.0..1.0, MetersDB
+// / PeaksDB / FloorsDB are dBFS. Levels are ballistics-processed; see meters.go.
+type StudioMeters struct {
+	SpkL float64 `json:"spkL"`
+	SpkR float64 `json:"spkR"`
+	PgmL float64 `json:"pgmL"`
+	PgmR float64 `json:"pgmR"`
+	RsrL float64 `json:"rsrL"`
+	RsrR float64 `json:"rsrR"`
 }
 
 func NewEngine(cfg *Config, version string, cfgPath string) *Engine {
+	return newEngine(cfg, version, cfgPath, "", "")
+}
+
+// newEngine builds an engine. An additional studio (v0.3.72) passes the
+// default engine's stateDir as parentState and keeps its state below it.
+func newEngine(cfg *Config, version string, cfgPath string, studio string, parentState string) *Engine {
 	e := &Engine{
+		studio:   studio,
 		cfg:      cfg,
 		version:  version,
 		cfgPath: cfgPath,
 		rc:       make(map[int]float64),
 		lastSent: make(map[int]float64),
-		upgrader: websocket.Upgrader{CheckOrigin: func(r *http.Request) bool { return true }},
-		clients:  make(map[*websocket.Conn]bool),
+		meters:       make(map[int]*meterChannel),
+		lastSentPeak: make(map[int]float64),
+		alarms:       make(map[string]*alarmState),
+		mockMixer:    newMockMixer(),
+		upgrader: websocket.Upgrader{
+			// v0.3.57: negotiate permessage-deflate for JSON frames (opt-out via ws.disable_compression).
+			EnableCompression: cfg == nil || !cfg.WS.DisableCompression,
+		},
+		clients:  make(map[*websocket.Conn]*wsClient),
+		stream:   newStreamHub(),
+		sessions: make(map[string]Session),
 	}
+	// v0.3.59: origin policy (ws.allowed_origins) replaces the old allow-all check.
+	e.upgrader.CheckOrigin = e.checkWSOrigin
 
 	// v0.2.48: derive stateDir from the config YAML path.
 	// Installer creates: /home/wlcb/.StudioB-UI/state
 	// Config lives at:   /home/wlcb/.StudioB-UI/config/config.yml
 	// We compute baseDir = parent(parent(YAMLPath)) and then stateDir = baseDir/state.
 	// This keeps behavior explicit and avoids hidden magic.
-	if cfg != nil && cfg.Meta.YAMLPath != "" {
+	if studio != "" {
+		// v0.3.72: each additional studio keeps its own state files under the
+		// default studio's state dir, wherever its config file lives.
+		if strings.TrimSpace(parentState) != "" {
+			e.stateDir = filepath.Join(parentState, "studios", studio)
+		}
+	} else if cfg != nil && cfg.Meta.YAMLPath != "" {
 		p := cfg.Meta.YAMLPath
 		// Best-effort absolute path.
 		if abs, err := filepath.Abs(p); err == nil {
@@ -225,11 +314,21 @@ func NewEngine(cfg *Config, version string, cfgPath string) *Engine {
 		base := filepath.Dir(filepath.Dir(p))
 		e.stateDir = filepath.Join(base, "state")
 	}
+	e.history = newMeterHistory(cfg, e.stateDir)
+	e.journal = newEventJournal(e.stateDir)
+	e.transcript = &ecpTranscript{}
+	e.conformance = &dspConformance{}
+	e.showLock = loadShowLock(e.stateDir)
+	e.RecordEvent("engine.start", SeverityInfo, map[string]any{"version": version})
 
 	// Initialize known RCs to sane defaults
 	for _, id := range cfg.RCAllowlist {
 		e.rc[id] = 0
 		e.lastSent[id] = math.NaN()
+		if isMeterRC(id) {
+			e.meters[id] = &meterChannel{}
+			e.lastSentPeak[id] = math.NaN()
+		}
 	}
 
 	// Friendly defaults for v1 UI
@@ -242,9 +341,23 @@ func NewEngine(cfg *Config, version string, cfgPath string) *Engine {
 	if e.allowed(560) {
 		e.rc[560] = 0
 	}
+	if isMockMode(*cfg) && cfg.Mock.Model == MockModelMixer {
+		e.initMockMixerDefaultsLocked()
+	}
 
-	// Start mock meter generator and publisher
+	// v0.3.65: optional boot scenario (mock mode only; failure is logged, not fatal).
+	if p := strings.TrimSpace(cfg.Mock.Scenario); p != "" && isMockMode(*cfg) {
+		if !filepath.IsAbs(p) && cfgPath != "" {
+			p = filepath.Join(filepath.Dir(cfgPath), p)
+		}
+		if _, err := e.startScenarioFile(p); err != nil {
+			log.Printf("mock scenario not started: %v", err)
+		}
+	}
+
+	// Start mock meter generator, meter ballistics stage and publisher
 	go e.mockLoop()
+	go e.meterLoop()
 	go e.publishLoop()
 	go e.dspMonitorLoop()
 	return e
@@ -261,7 +374,7 @@ func (e *Engine) allowed(id int) bool {
 	return false
 }
 
-func (e *Engine) SetRC(idStr string, value float64) error {
+func (e *Engine) SetRC(idStr string, value float64, who Identity) error {
 	id, err := resolveRC(idStr)
 	if err != nil {
 		return err
@@ -269,11 +382,35 @@ func (e *Engine) SetRC(idStr string, value float64) error {
 	if !e.allowed(id) {
 		return fmt.Errorf("rc %d not allowlisted", id)
 	}
+	// v0.3.76: per-control write policy (see control_policy.go).
+	if err := e.controlPolicyCheck("rc.set", id, value, who, ""); err != nil {
+		return err
+	}
+	// v0.3.77: in LIVE mode, canary controls are written to the DSP first
+	// (v0.3.78: or logged as a dry run in shadow mode).
+	if cfg := e.GetConfigCopy(); !isMeterRC(id) && dspModeWrites(cfg.DSP.Mode) {
+		source := who.Name
+		if source == "" {
+			source = "api"
+		}
+		if err := e.dspWriteControl(cfg, id, value, source); err != nil {
+			return err
+		}
+	}
+	e.storeRC(id, value)
+	return nil
+}
 
+// storeRC writes an already-checked value into the RC cache.
+func (e *Engine) storeRC(id int, value float64) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
+	if isMeterRC(id) {
+		// Meters are sources for the ballistics stage, not direct outputs.
+		e.setMeterRawLocked(id, value)
+		return
+	}
 	e.rc[id] = value
-	return nil
 }
 
 // ---------------------------------------------------------------------------
@@ -353,8 +490,18 @@ func (e *Engine) appendIntent(ev IntentEvent) error {
 //
 // SAFETY MODEL (DO NOT "CLEAN UP"):
 // - In mock mode, we NEVER send external control traffic; we only log + update cache.
-// - In live mode, this release is still strictly scoped to Speaker Mute only.
-func (e *Engine) ApplySpeakerMuteIntent(mute bool, source string) error {
+// - In live mode, only controls flagged live are written (v0.3.77).
+func (e *Engine) ApplySpeakerMuteIntent(mute bool, source string, who Identity) error {
+	spkMute := rcNameToID["STUB_SPK_MUTE"]
+	if !e.allowed(spkMute) {
+		return fmt.Errorf("rc %d not allowlisted", spkMute)
+	}
+	// v0.3.76: per-control write policy; a refusal is logged as a rejected
+	// intent and nothing else happens.
+	if err := e.controlPolicyCheck("speaker.mute", spkMute, boolRC(mute), who, source); err != nil {
+		return err
+	}
+
 	// Log first (audit trail). If logging fails, return the error.
 	// This makes failure visible, matching the "failures must be visible" philosophy.
 	ev := IntentEvent{
@@ -380,57 +527,52 @@ func (e *Engine) ApplySpeakerMuteIntent(mute bool, source string) error {
 	// Phase 2 (v0.2.76): when dsp.mode=live, perform a REAL DSP write.
 	//
 	// IMPORTANT SAFETY:
-	// - This release is still STRICTLY scoped to Speaker Mute only.
+	// - v0.3.77: only controls flagged live (canary, see dsp_canary.go) are
+	//   written; STUB_SPK_MUTE is live unless configured otherwise.
 	// - We do NOT change config, we do NOT auto-toggle modes.
 	// - In mock mode, we continue to behave like Phase 1 (log + cache only).
 	cfg := e.GetConfigCopy()
 	mode := strings.ToLower(strings.TrimSpace(cfg.DSP.Mode))
-	if mode == "live" {
-		// Attempt the write first. If it fails, do NOT update the cache.
+	if dspModeWrites(mode) {
+		// v0.3.78: shadow mode logs the exact command instead (dsp_shadow.go).
+		// If the write fails, do NOT update the cache.
 		// This keeps UI state truthful and prevents silent divergence.
-		resp, werr := e.ecpSendCSV("STUB_SPK_MUTE", val, 1200*time.Millisecond)
-		// Always append an explicit write audit record, even on failure.
+		if err := e.dspWriteControl(cfg, spkMute, val, source); err != nil {
+			return err
+		}
+	} else if werr := e.SimulatedWriteError(); werr != nil {
+		// v0.3.65: a mock scenario is simulating failed writes. Record it
+		// exactly like a live failure so drills exercise the same paths.
 		wev := IntentEvent{
 			TS:     time.Now().UTC().Format(time.RFC3339),
 			Action: "dsp.write",
 			Source: source,
 			Details: map[string]any{
-				"rc":     rcNameToID["STUB_SPK_MUTE"],
-				"name":   "STUB_SPK_MUTE",
-				"value":  val,
-				"ok":     (werr == nil),
-				"resp":   resp,
-				"target": strings.TrimSpace(cfg.DSP.Host) + ":" + itoa(cfg.DSP.Port),
+				"rc":        rcNameToID["STUB_SPK_MUTE"],
+				"name":      "STUB_SPK_MUTE",
+				"value":     val,
+				"ok":        false,
+				"simulated": true,
+				"error":     werr.Error(),
 			},
 		}
-		if werr != nil {
-			wev.Details["error"] = werr.Error()
-		}
 		if err := e.appendIntent(wev); err != nil {
-			// Logging failure must be visible.
 			return fmt.Errorf("dsp write log failed: %w", err)
 		}
-		// Record the attempt for the Engineering UI.
 		e.setLastDSPWrite(&DSPWriteStatus{
 			TS:    wev.TS,
 			Name:  "STUB_SPK_MUTE",
 			RC:    rcNameToID["STUB_SPK_MUTE"],
 			Value: val,
-			Ok:    (werr == nil),
-			Resp:  resp,
+			Ok:    false,
 			Mode:  mode,
-			Error: func() string { if werr != nil { return werr.Error() }; return "" }(),
+			Error: werr.Error(),
 		})
-		if werr != nil {
-			return fmt.Errorf("dsp write failed: %w", werr)
-		}
-		log.Printf("dsp write OK: STUB_SPK_MUTE=%v (resp=%s)", val, resp)
+		return fmt.Errorf("dsp write failed: %w", werr)
 	}
 
 	// Finally apply to the in-memory RC cache (used by the UI snapshot).
-	if err := e.SetRC("STUB_SPK_MUTE", val); err != nil {
-		return err
-	}
+	e.storeRC(spkMute, val)
 	log.Printf("intent applied: speaker.mute=%v (rc=%d source=%s mode=%s)", mute, rcNameToID["STUB_SPK_MUTE"], source, mode)
 	return nil
 }
@@ -449,6 +591,7 @@ func (e *Engine) StateSnapshot() map[string]any {
 // StudioStatusSnapshot returns a stable schema snapshot for the Studio UI.
 // This is intentionally separate from /api/state (debug) so the UI can depend on it.
 func (e *Engine) StudioStatusSnapshot() StudioStatus {
+	cfg := e.GetConfigCopy()
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
@@ -471,20 +614,67 @@ func (e *Engine) StudioStatusSnapshot() StudioStatus {
 	s.Meters.RsrL = e.rc[rcNameToID["STUB_RSR_L"]]
 	s.Meters.RsrR = e.rc[rcNameToID["STUB_RSR_R"]]
 
+	s.Peaks.PgmL = e.meterPeakLocked(rcNameToID["STUB_PGM_L"])
+	s.Peaks.PgmR = e.meterPeakLocked(rcNameToID["STUB_PGM_R"])
+	s.Peaks.SpkL = e.meterPeakLocked(rcNameToID["STUB_SPK_L"])
+	s.Peaks.SpkR = e.meterPeakLocked(rcNameToID["STUB_SPK_R"])
+	s.Peaks.RsrL = e.meterPeakLocked(rcNameToID["STUB_RSR_L"])
+	s.Peaks.RsrR = e.meterPeakLocked(rcNameToID["STUB_RSR_R"])
+
+	s.MetersDB.PgmL, s.PeaksDB.PgmL = e.meterDBLocked(rcNameToID["STUB_PGM_L"])
+	s.MetersDB.PgmR, s.PeaksDB.PgmR = e.meterDBLocked(rcNameToID["STUB_PGM_R"])
+	s.MetersDB.SpkL, s.PeaksDB.SpkL = e.meterDBLocked(rcNameToID["STUB_SPK_L"])
+	s.MetersDB.SpkR, s.PeaksDB.SpkR = e.meterDBLocked(rcNameToID["STUB_SPK_R"])
+	s.MetersDB.RsrL, s.PeaksDB.RsrL = e.meterDBLocked(rcNameToID["STUB_RSR_L"])
+	s.MetersDB.RsrR, s.PeaksDB.RsrR = e.meterDBLocked(rcNameToID["STUB_RSR_R"])
+
+	floor := func(name string) float64 { return meterCalibrationFor(cfg, rcNameToID[name]).floor() }
+	s.FloorsDB = StudioMeters{
+		PgmL: floor("STUB_PGM_L"), PgmR: floor("STUB_PGM_R"),
+		SpkL: floor("STUB_SPK_L"), SpkR: floor("STUB_SPK_R"),
+		RsrL: floor("STUB_RSR_L"), RsrR: floor("STUB_RSR_R"),
+	}
+
+	s.Alarms = e.activeAlarmsLocked()
+	if l := e.ShowLockStatus(); l.Locked {
+		s.ShowLock = &l
+	}
+
 	return s
 }
 
 func (e *Engine) HandleWS(w http.ResponseWriter, r *http.Request) {
+	// v0.3.59: bind the socket to a role before upgrading so a rejected
+	// handshake gets a plain HTTP error the client can read.
+	id, err := e.ResolveIdentity(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
 	c, err := e.upgrader.Upgrade(w, r, nil)
 	if err != nil {
+		// Upgrade already replied (e.g. 403 for a disallowed Origin).
+		log.Printf("ws: upgrade refused for %s (origin=%q): %v", r.RemoteAddr, r.Header.Get("Origin"), err)
 		return
 	}
+	cl := &wsClient{conn: c, meterEnc: parseMeterEncoding(r), topics: parseTopics(r), id: id}
+
+	// Register first so no delta published from here on is missed; holding
+	// writeMu queues those deltas behind the snapshot and meter index.
+	cl.writeMu.Lock()
 	e.clientsMu.Lock()
-	e.clients[c] = true
+	e.clients[c] = cl
 	e.clientsMu.Unlock()
 
 	// Send immediate snapshot
-	_ = c.WriteJSON(map[string]any{"type": "snapshot", "data": e.StateSnapshot()})
+	b, _ := json.Marshal(map[string]any{"type": "snapshot", "data": e.StateSnapshot()})
+	_ = cl.writeTextLocked(b)
+	sess, _ := json.Marshal(map[string]any{"type": "session", "role": id.Role, "name": id.Name, "via": id.Via})
+	_ = cl.writeTextLocked(sess)
+	if cl.meterEnc != 0 && wantsTopic(cl.topics, TopicMeters) {
+		_ = cl.writeTextLocked(meterIndexMessage(cl.meterEnc, e.meterIndex()))
+	}
+	cl.writeMu.Unlock()
 
 	// Keep alive / read pump
 	go func() {
@@ -503,37 +693,170 @@ func (e *Engine) HandleWS(w http.ResponseWriter, r *http.Request) {
 	}()
 }
 
-func (e *Engine) broadcast(v any) {
+// broadcastTopic sends one JSON message to every client subscribed to topic.
+func (e *Engine) broadcastTopic(topic string, v any) {
 	b, _ := json.Marshal(v)
 	e.clientsMu.Lock()
 	defer e.clientsMu.Unlock()
-	for c := range e.clients {
-		_ = c.SetWriteDeadline(time.Now().Add(2 * time.Second))
-		if err := c.WriteMessage(websocket.TextMessage, b); err != nil {
+	for c, cl := range e.clients {
+		if !wantsTopic(cl.topics, topic) {
+			continue
+		}
+		if err := cl.writeText(b); err != nil {
+			_ = c.Close()
+			delete(e.clients, c)
+		}
+	}
+}
+
+// meterDelta is the meters-topic part of one publish tick.
+type meterDelta struct {
+	level   map[int]float64 // normalized levels that moved past the deadband
+	peak    map[int]float64 // normalized held peaks that moved
+	levelDB map[int]float64 // dBFS for the ids in level
+	peakDB  map[int]float64 // dBFS for the ids in peak
+
+	// frame holds every meter in index order for binary clients:
+	// levels, peaks, level dBFS, peak dBFS.
+	frame []float64
+}
+
+func (m meterDelta) empty() bool { return len(m.level) == 0 && len(m.peak) == 0 }
+
+// broadcastDelta sends one publish tick to every client in its own format.
+//
+// Legacy JSON clients (no ?meters=, no ?topics=) get the full delta
+// (controls + meters) exactly as before. Otherwise controls and meters are
+// split by topic, and binary-meter clients get one packed frame carrying every
+// meter in index order if any meter moved.
+// Each payload is encoded at most once per tick, not once per client.
+func (e *Engine) broadcastDelta(ctl map[int]float64, md meterDelta, now time.Time) {
+	var legacy, controls, meterJSON []byte
+	frames := map[byte][]byte{}
+	encode := func(dst *[]byte, rc map[int]float64, m meterDelta) []byte {
+		if *dst == nil {
+			*dst, _ = json.Marshal(deltaMessage(rc, m, now))
+		}
+		return *dst
+	}
+
+	e.clientsMu.Lock()
+	defer e.clientsMu.Unlock()
+	for c, cl := range e.clients {
+		var err error
+		wantCtl := wantsTopic(cl.topics, TopicControls) && len(ctl) > 0
+		wantMtr := wantsTopic(cl.topics, TopicMeters) && !md.empty()
+		switch {
+		case wantCtl && wantMtr && cl.meterEnc == 0:
+			if legacy == nil {
+				all := make(map[int]float64, len(ctl)+len(md.level))
+				for id, v := range ctl {
+					all[id] = v
+				}
+				for id, v := range md.level {
+					all[id] = v
+				}
+				encode(&legacy, all, md)
+			}
+			err = cl.writeText(legacy)
+		default:
+			if wantCtl {
+				err = cl.writeText(encode(&controls, ctl, meterDelta{}))
+			}
+			if err == nil && wantMtr {
+				if cl.meterEnc == 0 {
+					err = cl.writeText(encode(&meterJSON, md.level, md))
+				} else {
+					f, ok := frames[cl.meterEnc]
+					if !ok {
+						f = encodeMeterFrame(cl.meterEnc, md.frame, now)
+						frames[cl.meterEnc] = f
+					}
+					err = cl.writeBinary(f)
+				}
+			}
+		}
+		if err != nil {
 			_ = c.Close()
 			delete(e.clients, c)
 		}
 	}
 }
 
+// deltaMessage builds the shared delta payload. Meter extras ride along when
+// present: "peak" (held peaks, v0.3.60) and "db" / "peakDb" (dBFS, v0.3.61),
+// all keyed like "rc".
+func deltaMessage(rc map[int]float64, m meterDelta, now time.Time) map[string]any {
+	msg := map[string]any{"type": "delta", "rc": rc, "t": now.UnixMilli()}
+	if len(m.levelDB) > 0 {
+		msg["db"] = m.levelDB
+	}
+	if len(m.peak) > 0 {
+		msg["peak"] = m.peak
+		msg["peakDb"] = m.peakDB
+	}
+	return msg
+}
+
 func (e *Engine) publishLoop() {
 	ticker := time.NewTicker(time.Second / time.Duration(e.cfg.Meters.PublishHz))
 	defer ticker.Stop()
 
+	index := e.meterIndex()
 	for range ticker.C {
+		// A config reload can change the allowlisted meters; binary clients
+		// get the new index before the first frame laid out by it.
+		if next := e.meterIndex(); !slices.Equal(next, index) {
+			index = next
+			e.broadcastMeterIndex(index)
+		}
 		e.mu.Lock()
-		delta := make(map[int]float64)
+		ctl := make(map[int]float64)
+		md := meterDelta{
+			level:   make(map[int]float64),
+			peak:    make(map[int]float64),
+			levelDB: make(map[int]float64),
+			peakDB:  make(map[int]float64),
+		}
 		for id, val := range e.rc {
 			last := e.lastSent[id]
 			if math.IsNaN(last) || math.Abs(val-last) >= e.cfg.Meters.Deadband {
-				delta[id] = val
+				if isMeterRC(id) {
+					md.level[id] = val
+					md.levelDB[id], _ = e.meterDBLocked(id)
+				} else {
+					ctl[id] = val
+				}
 				e.lastSent[id] = val
 			}
 		}
+		for id, ch := range e.meters {
+			last := e.lastSentPeak[id]
+			if math.IsNaN(last) || math.Abs(ch.peakNorm-last) >= e.cfg.Meters.Deadband {
+				md.peak[id] = ch.peakNorm
+				md.peakDB[id] = ch.peakDB
+				e.lastSentPeak[id] = ch.peakNorm
+			}
+		}
+		// Binary frames carry every meter, four sections in index order.
+		n := len(index)
+		md.frame = make([]float64, 4*n)
+		for i, id := range index {
+			md.frame[i] = e.rc[id]
+			md.frame[n+i] = e.meterPeakLocked(id)
+			md.frame[2*n+i], md.frame[3*n+i] = e.meterDBLocked(id)
+		}
 		e.mu.Unlock()
 
-		if len(delta) > 0 {
-			e.broadcast(map[string]any{"type": "delta", "rc": delta, "t": time.Now().UnixMilli()})
+		now := time.Now()
+		if len(ctl) > 0 {
+			e.streamPublish(TopicControls, deltaMessage(ctl, meterDelta{}, now))
+		}
+		if !md.empty() {
+			e.streamPublish(TopicMeters, deltaMessage(md.level, md, now))
+		}
+		if len(ctl) > 0 || !md.empty() {
+			e.broadcastDelta(ctl, md, now)
 		}
 	}
 }
@@ -541,13 +864,41 @@ func (e *Engine) publishLoop() {
 // Mock loop generates plausible meter motion for v1 UI testing.
 func (e *Engine) mockLoop() {
 	rand.Seed(time.Now().UnixNano())
+	last := time.Now()
 	for {
+		now := time.Now()
+		dt := now.Sub(last).Seconds()
+		last = now
+		cfg := e.GetConfigCopy()
+		model := cfg.Mock.Model
+		mock := isMockMode(cfg)
 		e.mu.Lock()
+		// v0.3.65: a running scenario drives its meters and events
+		// (mock mode only; scripted faults never reach live or shadow).
+		var due []scenarioEvent
+		var finished *scenarioRun
+		if mock {
+			due, finished = e.scenarioTickLocked(now)
+		}
+		scripted := func(id int) bool {
+			if e.scenario == nil {
+				return false
+			}
+			_, ok := e.scenario.sc.meters[id]
+			return ok
+		}
+		// v0.3.66: meters follow the mock console (faders, mutes) by default.
+		if model != MockModelRandom {
+			e.mockMixerStepLocked(now, dt, scripted)
+		}
 		// meters: 411/412 program, 460/461 speakers, 462/463 rs return
 		meterIDs := []int{411, 412, 460, 461, 462, 463}
 		for _, id := range meterIDs {
-			// random walk
-			cur := e.rc[id]
+			if model != MockModelRandom || scripted(id) {
+				continue
+			}
+			// random walk (raw source; ballistics are applied by meterLoop)
+			cur := e.meterRawLocked(id)
 			step := (rand.Float64() - 0.5) * 0.15
 			next := cur + step
 			if next < 0 {
@@ -556,10 +907,10 @@ func (e *Engine) mockLoop() {
 			if next > 1 {
 				next = 1
 			}
-			e.rc[id] = next
+			e.setMeterRawLocked(id, next)
 		}
-		// indicator 560 toggles occasionally
-		if rand.Intn(200) == 0 {
+		// indicator 560 toggles occasionally (scenarios script it instead)
+		if e.scenario == nil && rand.Intn(200) == 0 {
 			if e.rc[560] < 0.5 {
 				e.rc[560] = 1
 			} else {
@@ -567,6 +918,7 @@ func (e *Engine) mockLoop() {
 			}
 		}
 		e.mu.Unlock()
+		e.applyScenarioEvents(due, finished)
 
 		time.Sleep(50 * time.Millisecond)
 	}
@@ -585,11 +937,6 @@ type UpdateInfo struct {
 	Notes           string `json:"notes,omitempty"`
 }
 
-// Operator-safe reconnect (stub for v1)
-func (e *Engine) Reconnect() {
-	log.Printf("reconnect requested (mode=%s)", e.cfg.DSP.Mode)
-}
-
 // ReloadConfig reloads the YAML config and re-applies JSON/env overrides.
 //
 // This is intentionally conservative: it only changes in-memory config.
@@ -625,16 +972,17 @@ func (e *Engine) ReloadConfig() error {
 //
 // SAFETY:
 // - Switching to non-live always disarms writes immediately.
-// - Switching to live attempts to arm writes, but only succeeds if DSP is connected.
-//   If arming fails, desired mode remains "live" (config truth) but ActiveMode remains "mock".
+// - Switching to live does NOT arm writes; an admin arms them with a TTL via
+//   POST /api/dsp/arm (v0.3.74). Until then ActiveMode remains "mock".
 func (e *Engine) ReloadConfigFrom(path string) error {
 	cfgPath := strings.TrimSpace(path)
 	if cfgPath == "" {
 		return fmt.Errorf("cannot reload config: empty path")
 	}
 
-	newCfg, err := LoadConfig(cfgPath)
+	newCfg, err := e.loadOwnConfig(cfgPath)
 	if err != nil {
+		e.RecordEvent("config.reload", SeverityError, map[string]any{"path": cfgPath, "ok": false, "error": err.Error()})
 		return err
 	}
 
@@ -646,22 +994,26 @@ func (e *Engine) ReloadConfigFrom(path string) error {
 	// Persist the canonical path we are now using (for future reloads + transparency).
 	e.cfgPath = cfgPath
 
-	desired := strings.ToLower(strings.TrimSpace(newCfg.DSP.Mode))
-	if desired != "live" {
-		// Safe fallback.
-		e.DisarmDSPLive()
-	} else {
-		// Operator explicitly requested LIVE writes. Attempt to arm.
-		// If this fails (e.g. DSP disconnected), we log it and remain disarmed.
-		if err := e.ArmDSPLive(); err != nil {
-			log.Printf("LIVE requested but could not arm writes: %v", err)
-			// Ensure we're disarmed (explicit).
-			e.DisarmDSPLive()
-		}
-	}
+	// v0.3.74: a reload never arms LIVE writes (that takes POST /api/dsp/arm);
+	// it disarms when the mode or DSP target changed under the arm.
+	e.disarmIfDSPConfigChanged()
+	e.stopScenarioUnlessMock()
 
 	log.Printf("config reloaded from %s (desired=%s host=%s port=%d liveArmed=%v)",
 		cfgPath, newCfg.DSP.Mode, newCfg.DSP.Host, newCfg.DSP.Port, e.DSPLiveActive())
+	details := map[string]any{
+		"path": cfgPath,
+		"ok":   true,
+		"mode": newCfg.DSP.Mode,
+		"host": newCfg.DSP.Host,
+		"port": newCfg.DSP.Port,
+	}
+	sev := SeverityInfo
+	if len(newCfg.Meta.Warnings) > 0 {
+		details["warnings"] = newCfg.Meta.Warnings
+		sev = SeverityWarn
+	}
+	e.RecordEvent("config.reload", sev, details)
 	return nil
 }
 
@@ -844,7 +1196,23 @@ func (e *Engine) UpdateSync() (string, error) {
 	// not the underlying script filename ("admin-update.sh").
 	// Passing the filename causes the engine to reject the action and the UI update
 	// will fail with: "unknown admin action: admin-update.sh".
-	return e.runAdminScriptWithResult("update")
+	e.RecordEvent("admin.update", SeverityInfo, map[string]any{"state": "started"})
+	out, err := e.runAdminScriptWithResult("update")
+	e.recordAdminResult("update", out, err)
+	return out, err
+}
+
+// recordAdminResult journals the outcome of an admin script (v0.3.70).
+func (e *Engine) recordAdminResult(action, out string, err error) {
+	if err != nil {
+		e.RecordEvent("admin."+action, SeverityError, map[string]any{
+			"state":      "failed",
+			"error":      err.Error(),
+			"outputTail": tailLines(out, 5),
+		})
+		return
+	}
+	e.RecordEvent("admin."+action, SeverityInfo, map[string]any{"state": "ok"})
 }
 
 func (e *Engine) Update() {
@@ -982,6 +1350,7 @@ func (e *Engine) runAdminScriptWithResult(action string, args ...string) (string
 // It logs the output and returns no error.
 func (e *Engine) runAdminScript(action string, args ...string) {
 	out, err := e.runAdminScriptWithResult(action, args...)
+	e.recordAdminResult(action, out, err)
 	if err != nil {
 		log.Printf("%s failed: %v\n%s", action, err, out)
 		return
@@ -1134,7 +1503,12 @@ type DSPWriteStatus struct {
 	Ok    bool    `json:"ok"`
 	Resp  string  `json:"resp,omitempty"`
 	Error string  `json:"error,omitempty"`
-	Mode  string  `json:"mode,omitempty"` // "live" or "mock" at time of attempt
+	Mode  string  `json:"mode,omitempty"` // "live", "shadow" or "mock" at time of attempt
+	// v0.3.78: shadow mode dry runs (nothing was sent).
+	DryRun  bool   `json:"dryRun,omitempty"`
+	Command string `json:"command,omitempty"`
+	Target  string `json:"target,omitempty"`
+	Expect  string `json:"expect,omitempty"`
 }
 
 type DSPModeStatus struct {
@@ -1142,6 +1516,12 @@ type DSPModeStatus struct {
 	ActiveMode  string `json:"activeMode"`
 	LiveArmed   bool   `json:"liveArmed"`
 	LiveArmedAt string `json:"liveArmedAt,omitempty"`
+	// v0.3.74: expiry, reason and who armed (see dsp_arming.go).
+	Arm DSPArmStatus `json:"arm"`
+	// v0.3.77: controls with real DSP writes in LIVE mode (see dsp_canary.go).
+	LiveControls []LiveControl `json:"liveControls"`
+	// v0.3.81: last design conformance report (see dsp_conformance.go).
+	Conformance *DSPConformanceReport `json:"conformance,omitempty"`
 
 	Host          string `json:"host,omitempty"`
 	Port          int    `json:"port,omitempty"`
@@ -1179,13 +1559,17 @@ func (e *Engine) DSPModeStatus() DSPModeStatus {
 	// has successfully armed live writes (DSP connected, etc.). This prevents the UI from
 	// claiming "live" when the engine is still safely disarmed.
 	active := "mock"
+	arm := e.DSPArmStatus()
 	liveArmed := e.DSPLiveActive()
 	if liveArmed {
 		active = "live"
 	}
+	if desired == DSPModeShadow {
+		active = DSPModeShadow
+	}
 	var lts string
-	if liveArmed && !e.dspLiveArmedAt.IsZero() {
-		lts = e.dspLiveArmedAt.UTC().Format(time.RFC3339)
+	if liveArmed {
+		lts = arm.ArmedAt
 	}
 
 	return DSPModeStatus{
@@ -1193,6 +1577,9 @@ func (e *Engine) DSPModeStatus() DSPModeStatus {
 		ActiveMode:    active,
 		LiveArmed:     liveArmed,
 		LiveArmedAt:   lts,
+		Arm:           arm,
+		LiveControls:  cfg.LiveControls(),
+		Conformance:   e.DSPConformance(),
 		Host:          host,
 		Port:          port,
 		Validated:     validated,
@@ -1207,10 +1594,7 @@ func (e *Engine) DSPModeStatus() DSPModeStatus {
 func (e *Engine) dspConfigSignature() string {
 	// Use a snapshot to avoid races.
 	c := e.GetConfigCopy()
-	mode := strings.ToLower(strings.TrimSpace(c.DSP.Mode))
-	host := strings.TrimSpace(c.DSP.Host)
-	port := c.DSP.Port
-	return mode + "|" + host + "|" + itoa(port)
+	return dspConfigSignatureFrom(&c)
 }
 
 // ---------------------------------------------------------------------------
@@ -1250,7 +1634,12 @@ func dspConfigSignatureFrom(cfg *Config) string {
 	mode := strings.ToLower(strings.TrimSpace(cfg.DSP.Mode))
 	host := strings.TrimSpace(cfg.DSP.Host)
 	port := cfg.DSP.Port
-	return mode + "|" + host + "|" + itoa(port)
+	sig := mode + "|" + host + "|" + itoa(port)
+	// v0.3.71: backup Cores are part of the DSP target too.
+	for _, b := range cfg.DSP.Backups {
+		sig += "|" + b.addr()
+	}
+	return sig
 }
 
 // ApplyConfig updates the running engine's config pointer in-memory.
@@ -1276,74 +1665,14 @@ func (e *Engine) ApplyConfig(newCfg *Config) {
 		e.dsp.state = DSPHealthUnknown
 		e.dsp.lastErr = ""
 		e.dsp.failures = 0
+		e.dsp.successes = 0
+		e.dsp.reason = ""
 		e.dsp.lastTestAt = time.Time{}
+		e.dsp.cores = nil
+		e.dsp.activeCore = nil
 		e.dspMu.Unlock()
+		e.disarmIfDSPConfigChanged()
 	}
-}
-
-// ArmDSPLive enables DSP *write* operations when the desired mode is LIVE.
-// Monitoring remains read-only regardless of this flag.
-//
-// Preconditions (conservative):
-// - Desired mode (config) must be "live"
-// - DSP must be currently connected (based on cached health)
-//
-// No auto-testing is performed here; the always-on monitor already provides
-// a continuously updated connectivity state.
-func (e *Engine) ArmDSPLive() error {
-	cfg := e.GetConfigCopy()
-	desired := strings.ToLower(strings.TrimSpace(cfg.DSP.Mode))
-	if desired != "live" {
-		return fmt.Errorf("cannot enter live: config dsp.mode is %q (set to 'live' first)", cfg.DSP.Mode)
-	}
-
-	e.ensureDSPHealthInit()
-	e.dspMu.Lock()
-	defer e.dspMu.Unlock()
-
-	if e.dsp.state == DSPHealthDisconnected {
-		return fmt.Errorf("cannot enter live: DSP is disconnected")
-	}
-
-	e.dspLiveArmed = true
-	e.dspLiveArmedAt = time.Now().UTC()
-	return nil
-}
-
-// DisarmDSPLive disables DSP write operations (safe fallback).
-func (e *Engine) DisarmDSPLive() {
-	e.dspMu.Lock()
-	e.dspLiveArmed = false
-	e.dspLiveArmedAt = time.Time{}
-	e.dspMu.Unlock()
-}
-
-// DSPLiveActive reports whether DSP writes are currently armed.
-func (e *Engine) DSPLiveActive() bool {
-    // In this project, "live" vs "mock" is about whether we *should* talk to the DSP.
-    //
-    // Earlier versions used an in-memory "arming" flag (set after a manual test) to decide
-    // whether live was "active". That caused confusing behavior after engine restarts: the
-    // desired mode would remain "live" (persisted), but the UI would show "MOCK" until you
-    // manually re-armed.
-    //
-    // We now treat live as active whenever:
-    //   1) desired mode is live, AND
-    //   2) the DSP is not in a disconnected state.
-    //
-    // This matches DSPControlAllowed(), which already gates writes on connectivity rather
-    // than an in-memory flag.
-
-    e.cfgMu.Lock()
-    desired := e.cfg.DSP.Mode
-    e.cfgMu.Unlock()
-
-    if !strings.EqualFold(desired, "live") {
-        return false
-    }
-
-    // Snapshot is safe and already handles nil DSP state.
-    snap := e.DSPHealthSnapshot()
-    return snap.State != DSPHealthDisconnected
+	e.stopScenarioUnlessMock()
 }
 
@@ -0,0 +1,222 @@
+package app
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Runtime event journal (v0.3.70)
+//
+// Runtime events used to live in several places (DSP timeline, alarm files,
+// log.Printf) or only in the browser's "Recent Runtime Events" list, which is
+// lost on reload. Every engine event now goes through RecordEvent, which:
+//
+//  1. appends {id, time, type, severity, details} to state/events.jsonl
+//     (bounded to journalMaxLines), and
+//  2. publishes it on the "events" topic (WS + SSE) with the same id.
+//
+// PublishEvent remains as the info-severity shorthand, so every existing
+// event kind (dsp.health, alarm.*, config.reload, mock.scenario) is journaled.
+//
+// GET /api/events?n=&type=&severity=&since= reads the journal back.
+// ---------------------------------------------------------------------------
+
+const (
+	SeverityInfo  = "info"
+	SeverityWarn  = "warn"
+	SeverityError = "error"
+
+	journalMaxLines = 5000
+	// Bounding rewrites the file, so only do it every so often.
+	journalBoundEvery = 100
+)
+
+// JournalEntry is one recorded runtime event.
+type JournalEntry struct {
+	ID       int64          `json:"id"`
+	Time     string         `json:"time"`
+	Type     string         `json:"type"`
+	Severity string         `json:"severity"`
+	Details  map[string]any `json:"details,omitempty"`
+}
+
+// eventJournal is stored on Engine. Its mutex only orders ids and appends;
+// publishing happens after it is released.
+type eventJournal struct {
+	mu      sync.Mutex
+	seq     int64
+	appends int
+}
+
+// newEventJournal resumes the id sequence from the last line on disk.
+func newEventJournal(stateDir string) *eventJournal {
+	j := &eventJournal{}
+	if ents := readJournalFile(journalPath(stateDir)); len(ents) > 0 {
+		j.seq = ents[len(ents)-1].ID
+	}
+	return j
+}
+
+func journalPath(stateDir string) string {
+	if strings.TrimSpace(stateDir) == "" {
+		return ""
+	}
+	return filepath.Join(stateDir, "events.jsonl")
+}
+
+func severityRank(s string) int {
+	switch s {
+	case SeverityError:
+		return 2
+	case SeverityWarn:
+		return 1
+	}
+	return 0
+}
+
+// RecordEvent journals an event and publishes it on the events topic.
+// Callers MUST NOT hold engine locks (see PublishEvent).
+func (e *Engine) RecordEvent(typ, severity string, details map[string]any) {
+	if severityRank(severity) == 0 {
+		severity = SeverityInfo
+	}
+	now := time.Now().UTC()
+	j := e.journal
+	j.mu.Lock()
+	j.seq++
+	ent := JournalEntry{
+		ID:       j.seq,
+		Time:     now.Format(time.RFC3339),
+		Type:     typ,
+		Severity: severity,
+		Details:  details,
+	}
+	if path := journalPath(e.stateDir); path != "" {
+		_ = os.MkdirAll(filepath.Dir(path), 0755)
+		if f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644); err == nil {
+			line, _ := json.Marshal(ent)
+			_, _ = f.Write(append(line, '\n'))
+			_ = f.Close()
+		}
+		j.appends++
+		if j.appends%journalBoundEvery == 0 {
+			e.boundDSPTimeline(path, journalMaxLines)
+		}
+	}
+	j.mu.Unlock()
+
+	payload := map[string]any{
+		"type":     "event",
+		"id":       ent.ID,
+		"kind":     typ,
+		"severity": severity,
+		"ts":       ent.Time,
+		"details":  details,
+	}
+	e.streamPublish(TopicEvents, payload)
+	e.broadcastTopic(TopicEvents, payload)
+}
+
+// JournalQuery filters ReadJournal.
+type JournalQuery struct {
+	N        int    // newest N matches (default 100)
+	Type     string // exact type, or prefix when it ends in "." or "*"
+	Severity string // minimum severity
+	SinceID  int64  // only ids greater than this
+	Since    time.Time
+}
+
+// ParseJournalQuery reads ?n=&type=&severity=&since= ; since is an event id
+// or an RFC3339 time.
+func ParseJournalQuery(q map[string][]string) JournalQuery {
+	get := func(k string) string {
+		if v := q[k]; len(v) > 0 {
+			return strings.TrimSpace(v[0])
+		}
+		return ""
+	}
+	jq := JournalQuery{N: 100, Type: get("type"), Severity: strings.ToLower(get("severity"))}
+	if n, err := strconv.Atoi(get("n")); err == nil {
+		jq.N = n
+	}
+	jq.N = min(max(jq.N, 1), 1000)
+	if s := get("since"); s != "" {
+		if id, err := strconv.ParseInt(s, 10, 64); err == nil {
+			jq.SinceID = id
+		} else if t, err := time.Parse(time.RFC3339, s); err == nil {
+			jq.Since = t
+		}
+	}
+	return jq
+}
+
+func (q JournalQuery) match(ent JournalEntry) bool {
+	if ent.ID <= q.SinceID {
+		return false
+	}
+	if !q.Since.IsZero() {
+		t, err := time.Parse(time.RFC3339, ent.Time)
+		if err != nil || t.Before(q.Since) {
+			return false
+		}
+	}
+	if q.Severity != "" && severityRank(ent.Severity) < severityRank(q.Severity) {
+		return false
+	}
+	switch {
+	case q.Type == "":
+	case strings.HasSuffix(q.Type, "*"):
+		return strings.HasPrefix(ent.Type, strings.TrimSuffix(q.Type, "*"))
+	case strings.HasSuffix(q.Type, "."):
+		return strings.HasPrefix(ent.Type, q.Type)
+	default:
+		return ent.Type == q.Type
+	}
+	return true
+}
+
+// ReadJournal returns matching entries, oldest first.
+func (e *Engine) ReadJournal(q JournalQuery) []JournalEntry {
+	e.journal.mu.Lock()
+	ents := readJournalFile(journalPath(e.stateDir))
+	e.journal.mu.Unlock()
+
+	out := []JournalEntry{}
+	for _, ent := range ents {
+		if q.match(ent) {
+			out = append(out, ent)
+		}
+	}
+	if len(out) > q.N {
+		out = out[len(out)-q.N:]
+	}
+	return out
+}
+
+func readJournalFile(path string) []JournalEntry {
+	if path == "" {
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	var out []JournalEntry
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		var ent JournalEntry
+		if json.Unmarshal(sc.Bytes(), &ent) == nil && ent.ID > 0 {
+			out = append(out, ent)
+		}
+	}
+	return out
+}
@@ -0,0 +1,398 @@
+package app
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Meter history / trends (v0.3.64)
+//
+// Meters used to exist only as the live value in e.rc. The engine now records
+// the processed level (dBFS, after ballistics + calibration) of each meter in
+// meters.history.meters into min/max/avg buckets at three resolutions:
+//
+//	res  bucket  default retention
+//	1s   1 s     1 hour    (memory only)
+//	1m   1 min   24 hours  (state/meter_history_1m.jsonl)
+//	1h   1 hour  30 days   (state/meter_history_1h.jsonl)
+//
+// Every resolution aggregates raw meter steps directly (no roll-up error).
+// Closed 1m/1h buckets are appended to their JSONL file and reloaded at
+// startup. When a file grows past twice its retention it is rewritten from
+// memory, so disk use stays bounded without rewriting on every append.
+//
+// GET /api/meters/history serves JSON or InfluxDB line protocol (format=influx).
+// ---------------------------------------------------------------------------
+
+// MeterBucket is one aggregated interval. T is the bucket start (unix seconds).
+type MeterBucket struct {
+	Meter int     `json:"m"`
+	T     int64   `json:"t"`
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Avg   float64 `json:"avg"`
+	N     int     `json:"n"`
+
+	sum float64
+}
+
+func (b *MeterBucket) add(v float64) {
+	if b.N == 0 || v < b.Min {
+		b.Min = v
+	}
+	if b.N == 0 || v > b.Max {
+		b.Max = v
+	}
+	b.N++
+	b.sum += v
+	b.Avg = b.sum / float64(b.N)
+}
+
+// rounded returns b with values rounded to 0.01 dB for output and storage.
+func (b MeterBucket) rounded() MeterBucket {
+	r := func(v float64) float64 { return math.Round(v*100) / 100 }
+	b.Min, b.Max, b.Avg = r(b.Min), r(b.Max), r(b.Avg)
+	return b
+}
+
+// meterSample is one processed level collected under e.mu.
+type meterSample struct {
+	id int
+	db float64
+}
+
+// historyRes is one resolution's ring for every recorded meter.
+type historyRes struct {
+	name    string
+	step    time.Duration
+	keep    int
+	persist bool
+
+	open    map[int]*MeterBucket
+	closed  map[int][]MeterBucket
+	onDisk  int // lines in the JSONL file (persisted resolutions only)
+	pending []MeterBucket
+}
+
+// meterHistory is stored on Engine and guarded by its own mutex.
+type meterHistory struct {
+	mu  sync.Mutex
+	ids map[int]bool
+	res []*historyRes
+}
+
+func newMeterHistory(cfg *Config, stateDir string) *meterHistory {
+	h := &meterHistory{ids: map[int]bool{}}
+	if cfg == nil || cfg.Meters.History.Disabled {
+		return h
+	}
+	hc := cfg.Meters.History
+	for _, k := range hc.Meters {
+		if id, err := resolveRC(k); err == nil && isMeterRC(id) {
+			h.ids[id] = true
+		}
+	}
+	h.res = []*historyRes{
+		{name: "1s", step: time.Second, keep: hc.Keep1s},
+		{name: "1m", step: time.Minute, keep: hc.Keep1m, persist: true},
+		{name: "1h", step: time.Hour, keep: hc.Keep1h, persist: true},
+	}
+	for _, r := range h.res {
+		r.open = map[int]*MeterBucket{}
+		r.closed = map[int][]MeterBucket{}
+		if r.persist {
+			r.load(historyPath(stateDir, r.name))
+		}
+	}
+	return h
+}
+
+func historyPath(stateDir, res string) string {
+	if strings.TrimSpace(stateDir) == "" {
+		return ""
+	}
+	return filepath.Join(stateDir, "meter_history_"+res+".jsonl")
+}
+
+// load restores closed buckets from disk (best-effort).
+func (r *historyRes) load(path string) {
+	if path == "" {
+		return
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		r.onDisk++
+		var b MeterBucket
+		if json.Unmarshal(sc.Bytes(), &b) != nil || b.N == 0 {
+			continue
+		}
+		b.sum = b.Avg * float64(b.N)
+		r.closed[b.Meter] = append(r.closed[b.Meter], b)
+	}
+	for id, bs := range r.closed {
+		sort.Slice(bs, func(i, j int) bool { return bs[i].T < bs[j].T })
+		if len(bs) > r.keep {
+			bs = bs[len(bs)-r.keep:]
+		}
+		r.closed[id] = bs
+	}
+}
+
+// wantsMeterHistory reports whether samples should be collected at all.
+func (e *Engine) wantsMeterHistory() bool {
+	return len(e.history.ids) > 0 && len(e.history.res) > 0
+}
+
+// meterHistorySamplesLocked collects the processed levels to record.
+// Caller MUST hold e.mu.
+func (e *Engine) meterHistorySamplesLocked() []meterSample {
+	out := make([]meterSample, 0, len(e.history.ids))
+	for id := range e.history.ids {
+		if ch, ok := e.meters[id]; ok {
+			out = append(out, meterSample{id: id, db: ch.db})
+		}
+	}
+	return out
+}
+
+// recordMeterHistory adds one meter step. Callers MUST NOT hold e.mu; closed
+// buckets may be written to disk here.
+func (e *Engine) recordMeterHistory(now time.Time, samples []meterSample) {
+	h := e.history
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, r := range h.res {
+		start := now.Truncate(r.step).Unix()
+		for _, s := range samples {
+			b := r.open[s.id]
+			if b != nil && b.T != start {
+				r.close(*b)
+				b = nil
+			}
+			if b == nil {
+				b = &MeterBucket{Meter: s.id, T: start}
+				r.open[s.id] = b
+			}
+			b.add(s.db)
+		}
+		if r.persist && len(r.pending) > 0 {
+			r.flush(historyPath(e.stateDir, r.name), len(h.ids))
+		}
+	}
+}
+
+func (r *historyRes) close(b MeterBucket) {
+	bs := append(r.closed[b.Meter], b)
+	if len(bs) > r.keep {
+		bs = bs[len(bs)-r.keep:]
+	}
+	r.closed[b.Meter] = bs
+	if r.persist {
+		r.pending = append(r.pending, b.rounded())
+	}
+}
+
+// flush appends pending buckets, compacting the file when it has grown past
+// twice the retention for all meters.
+func (r *historyRes) flush(path string, meters int) {
+	pending := r.pending
+	r.pending = nil
+	if path == "" {
+		return
+	}
+	_ = os.MkdirAll(filepath.Dir(path), 0755)
+	if r.onDisk+len(pending) > 2*r.keep*meters {
+		var all []MeterBucket
+		for _, bs := range r.closed {
+			all = append(all, bs...)
+		}
+		sort.Slice(all, func(i, j int) bool { return all[i].T < all[j].T })
+		tmp := path + ".tmp"
+		f, err := os.Create(tmp)
+		if err != nil {
+			log.Printf("meter history: %v", err)
+			return
+		}
+		w := bufio.NewWriter(f)
+		for _, b := range all {
+			line, _ := json.Marshal(b.rounded())
+			_, _ = w.Write(append(line, '\n'))
+		}
+		_ = w.Flush()
+		_ = f.Close()
+		if err := os.Rename(tmp, path); err == nil {
+			r.onDisk = len(all)
+		}
+		return
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	for _, b := range pending {
+		line, _ := json.Marshal(b)
+		if _, err := f.Write(append(line, '\n')); err == nil {
+			r.onDisk++
+		}
+	}
+}
+
+// MeterHistoryQuery selects buckets for /api/meters/history.
+type MeterHistoryQuery struct {
+	Meters []int // empty == every recorded meter
+	Res    string
+	From   time.Time
+	To     time.Time
+}
+
+// ParseMeterHistoryQuery reads ?meter=&from=&to=&res= .
+//
+// meter: RC name or id, comma-separated (default: all recorded meters)
+// res:   1s | 1m (default) | 1h
+// from/to: RFC3339 or unix seconds; to defaults to now, from to 360 buckets before to.
+func ParseMeterHistoryQuery(q map[string][]string) (MeterHistoryQuery, error) {
+	get := func(k string) string {
+		if v := q[k]; len(v) > 0 {
+			return strings.TrimSpace(v[0])
+		}
+		return ""
+	}
+	mq := MeterHistoryQuery{Res: get("res")}
+	if mq.Res == "" {
+		mq.Res = "1m"
+	}
+	step, ok := map[string]time.Duration{"1s": time.Second, "1m": time.Minute, "1h": time.Hour}[mq.Res]
+	if !ok {
+		return mq, fmt.Errorf("res must be one of 1s, 1m, 1h")
+	}
+	for _, k := range strings.Split(get("meter"), ",") {
+		if k = strings.TrimSpace(k); k == "" {
+			continue
+		}
+		id, err := resolveRC(k)
+		if err != nil || !isMeterRC(id) {
+			return mq, fmt.Errorf("unknown meter %q", k)
+		}
+		mq.Meters = append(mq.Meters, id)
+	}
+	parseT := func(s string) (time.Time, error) {
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return time.Unix(n, 0), nil
+		}
+		return time.Parse(time.RFC3339, s)
+	}
+	mq.To = time.Now()
+	if s := get("to"); s != "" {
+		t, err := parseT(s)
+		if err != nil {
+			return mq, fmt.Errorf("invalid to: %v", err)
+		}
+		mq.To = t
+	}
+	mq.From = mq.To.Add(-360 * step)
+	if s := get("from"); s != "" {
+		t, err := parseT(s)
+		if err != nil {
+			return mq, fmt.Errorf("invalid from: %v", err)
+		}
+		mq.From = t
+	}
+	if mq.From.After(mq.To) {
+		return mq, fmt.Errorf("from is after to")
+	}
+	return mq, nil
+}
+
+// MeterHistory returns buckets for q, oldest first, grouped by meter name.
+// The still-open bucket is included so the newest point is live.
+func (e *Engine) MeterHistory(q MeterHistoryQuery) (map[string][]MeterBucket, error) {
+	h := e.history
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var r *historyRes
+	for _, x := range h.res {
+		if x.name == q.Res {
+			r = x
+		}
+	}
+	if r == nil {
+		return nil, fmt.Errorf("meter history is disabled")
+	}
+	ids := q.Meters
+	if len(ids) == 0 {
+		for id := range h.ids {
+			ids = append(ids, id)
+		}
+		sort.Ints(ids)
+	}
+	from, to := q.From.Truncate(r.step).Unix(), q.To.Unix()
+	out := map[string][]MeterBucket{}
+	for _, id := range ids {
+		if !h.ids[id] {
+			continue
+		}
+		bs := []MeterBucket{}
+		for _, b := range r.closed[id] {
+			if b.T >= from && b.T <= to {
+				bs = append(bs, b.rounded())
+			}
+		}
+		if b := r.open[id]; b != nil && b.T >= from && b.T <= to {
+			bs = append(bs, b.rounded())
+		}
+		out[meterName(id)] = bs
+	}
+	return out, nil
+}
+
+// meterName returns the RC name for a meter id (or the id as text).
+func meterName(id int) string {
+	for n, v := range rcNameToID {
+		if v == id {
+			return n
+		}
+	}
+	return strconv.Itoa(id)
+}
+
+// WriteMeterHistoryInflux writes buckets as InfluxDB line protocol:
+//
+//	studiob_meter,meter=STUB_PGM_L,rc=411,res=1m min=-31.2,max=-8.4,avg=-18.9,count=3000i 1760781600000000000
+//
+// Values are dBFS; the timestamp is the bucket start in nanoseconds.
+func WriteMeterHistoryInflux(w io.Writer, res string, data map[string][]MeterBucket) {
+	names := make([]string, 0, len(data))
+	for n := range data {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		for _, b := range data[n] {
+			fmt.Fprintf(w, "studiob_meter,meter=%s,rc=%d,res=%s min=%s,max=%s,avg=%s,count=%di %d\n",
+				n, b.Meter, res, influxFloat(b.Min), influxFloat(b.Max), influxFloat(b.Avg), b.N,
+				time.Unix(b.T, 0).UnixNano())
+		}
+	}
+}
+
+func influxFloat(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
@@ -0,0 +1,260 @@
+package app
+
+import (
+	"math"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Server-side meter ballistics (v0.3.60)
+//
+// Tablets used to smooth raw meter values themselves, and different browsers
+// rendered them differently. The engine now runs one processing stage for
+// every meter so every screen shows identical meters:
+//
+//	source (mock / DSP) -> meterRaw -> ballistics -> e.rc (level) + peak -> publishLoop
+//
+// Sources MUST write meters through setMeterRawLocked(), never into e.rc.
+//
+// Integration modes (per meter, see MeterBallistics):
+//   - peak: instant attack, exponential fall with release_ms time constant
+//   - vu:   symmetric ~300 ms integration (65 ms time constant = 99% in 300 ms)
+//   - ppm:  near-instant attack, 24 dB fall in 2.8 s (~1 s time constant on
+//     amplitude, which is a straight line in dB)
+//
+// Peak hold: the highest raw value is held for peak_hold_ms, then falls back
+// toward the current level with the meter's release time constant.
+//
+// Calibration (v0.3.61): after ballistics, each level and peak is converted to
+// dBFS with the meter's MeterCalibration, so the UI's -12/0 reference marks
+// line up with the Core's meter blocks. The normalized 0..1 value is kept for
+// existing clients; for dB-unit sources it is the position between floor and 0 dBFS.
+// ---------------------------------------------------------------------------
+
+const (
+	MeterModePeak = "peak"
+	MeterModeVU   = "vu"
+	MeterModePPM  = "ppm"
+
+	MeterUnitsLinear   = "linear"
+	MeterUnitsDB       = "db"
+	MeterUnitsPosition = "position"
+
+	defaultMeterFloorDB = -60.0
+
+	// meterProcessInterval is the ballistics step. Time constants are applied
+	// using the measured dt, so jitter here does not change meter behavior.
+	meterProcessInterval = 20 * time.Millisecond
+)
+
+// meterChannel is the ballistics state for one meter RC. Guarded by e.mu.
+//
+// raw/level/peak are in source units; the norm/db fields are derived from
+// them once per processing step so readers never need the config.
+type meterChannel struct {
+	raw    float64
+	level  float64
+	peak   float64
+	peakAt time.Time
+
+	norm     float64
+	peakNorm float64
+	db       float64
+	peakDB   float64
+	// rawDB is the calibrated instantaneous value (no ballistics); the over
+	// alarm uses it so a VU/PPM integration cannot hide a short over.
+	rawDB float64
+}
+
+// meterTimeConstants returns attack/release time constants (seconds) for b.
+func meterTimeConstants(b MeterBallistics) (attack, release float64) {
+	switch b.Mode {
+	case MeterModeVU:
+		attack, release = 0.065, 0.065
+	case MeterModePPM:
+		attack, release = 0.002, 1.01
+	default:
+		attack, release = 0, 0.3
+	}
+	if b.AttackMs > 0 {
+		attack = b.AttackMs / 1000
+	}
+	if b.ReleaseMs > 0 {
+		release = b.ReleaseMs / 1000
+	}
+	return attack, release
+}
+
+// meterBallisticsFor merges meters.per_meter[<name or id>] over the default.
+func meterBallisticsFor(cfg Config, id int) MeterBallistics {
+	b := cfg.Meters.Ballistics
+	for k, o := range cfg.Meters.PerMeter {
+		oid, err := resolveRC(k)
+		if err != nil || oid != id {
+			continue
+		}
+		if o.Mode != "" {
+			b.Mode = o.Mode
+		}
+		if o.AttackMs > 0 {
+			b.AttackMs = o.AttackMs
+		}
+		if o.ReleaseMs > 0 {
+			b.ReleaseMs = o.ReleaseMs
+		}
+		if o.PeakHoldMs > 0 {
+			b.PeakHoldMs = o.PeakHoldMs
+		}
+	}
+	return b
+}
+
+// meterCalibrationFor returns meters.calibration[<name or id>] or the default.
+func meterCalibrationFor(cfg Config, id int) MeterCalibration {
+	for k, c := range cfg.Meters.Calibration {
+		if oid, err := resolveRC(k); err == nil && oid == id {
+			return c
+		}
+	}
+	return MeterCalibration{Units: MeterUnitsLinear, FloorDB: defaultMeterFloorDB}
+}
+
+// floor is the bottom of the meter's dBFS scale (floor_db, default -60).
+func (c MeterCalibration) floor() float64 {
+	if c.FloorDB >= 0 {
+		return defaultMeterFloorDB
+	}
+	return c.FloorDB
+}
+
+// dbfs converts a source value to dBFS, clamped at the floor.
+func (c MeterCalibration) dbfs(v float64) float64 {
+	floor := c.floor()
+	var db float64
+	switch c.Units {
+	case MeterUnitsDB:
+		db = v + c.OffsetDB
+	case MeterUnitsPosition:
+		db = floor*(1-v) + c.OffsetDB
+	default:
+		if v <= 0 {
+			return floor
+		}
+		db = 20*math.Log10(v) + c.OffsetDB
+	}
+	if math.IsNaN(db) || db < floor {
+		return floor
+	}
+	return db
+}
+
+// normalized returns the 0..1 value published as the meter's "rc" level.
+func (c MeterCalibration) normalized(v float64) float64 {
+	if c.Units != MeterUnitsDB {
+		return v
+	}
+	floor := c.floor()
+	n := (c.dbfs(v) - floor) / -floor
+	return math.Max(0, math.Min(1, n))
+}
+
+// smooth moves cur toward target with time constant tau over dt seconds.
+func smooth(cur, target, tau, dt float64) float64 {
+	if tau <= 0 {
+		return target
+	}
+	return target + (cur-target)*math.Exp(-dt/tau)
+}
+
+// setMeterRawLocked records a raw source value for a meter.
+// Caller MUST hold e.mu.
+func (e *Engine) setMeterRawLocked(id int, v float64) {
+	ch, ok := e.meters[id]
+	if !ok {
+		return
+	}
+	ch.raw = v
+}
+
+// meterRawLocked returns the last raw source value. Caller MUST hold e.mu.
+func (e *Engine) meterRawLocked(id int) float64 {
+	if ch, ok := e.meters[id]; ok {
+		return ch.raw
+	}
+	return 0
+}
+
+// meterPeakLocked returns the held peak (normalized). Caller MUST hold e.mu.
+func (e *Engine) meterPeakLocked(id int) float64 {
+	if ch, ok := e.meters[id]; ok {
+		return ch.peakNorm
+	}
+	return 0
+}
+
+// meterDBLocked returns the calibrated level and peak in dBFS.
+// Caller MUST hold e.mu.
+func (e *Engine) meterDBLocked(id int) (level, peak float64) {
+	if ch, ok := e.meters[id]; ok {
+		return ch.db, ch.peakDB
+	}
+	return defaultMeterFloorDB, defaultMeterFloorDB
+}
+
+// processMetersLocked runs one ballistics step. Caller MUST hold e.mu.
+func (e *Engine) processMetersLocked(cfg Config, now time.Time, dt float64) {
+	for id, ch := range e.meters {
+		b := meterBallisticsFor(cfg, id)
+		attack, release := meterTimeConstants(b)
+
+		if ch.raw > ch.level {
+			ch.level = smooth(ch.level, ch.raw, attack, dt)
+		} else {
+			ch.level = smooth(ch.level, ch.raw, release, dt)
+		}
+
+		hold := time.Duration(b.PeakHoldMs * float64(time.Millisecond))
+		switch {
+		case ch.raw >= ch.peak:
+			ch.peak = ch.raw
+			ch.peakAt = now
+		case now.Sub(ch.peakAt) > hold:
+			ch.peak = smooth(ch.peak, ch.level, release, dt)
+		}
+		if ch.peak < ch.level {
+			ch.peak = ch.level
+		}
+
+		cal := meterCalibrationFor(cfg, id)
+		ch.norm = cal.normalized(ch.level)
+		ch.peakNorm = cal.normalized(ch.peak)
+		ch.db = cal.dbfs(ch.level)
+		ch.peakDB = cal.dbfs(ch.peak)
+		ch.rawDB = cal.dbfs(ch.raw)
+		e.rc[id] = ch.norm
+	}
+}
+
+// meterLoop is the processing stage between the meter sources and publishLoop.
+func (e *Engine) meterLoop() {
+	t := time.NewTicker(meterProcessInterval)
+	defer t.Stop()
+	last := time.Now()
+	for now := range t.C {
+		dt := now.Sub(last).Seconds()
+		last = now
+		cfg := e.GetConfigCopy()
+		e.mu.Lock()
+		e.processMetersLocked(cfg, now, dt)
+		trs := e.evaluateAlarmsLocked(cfg, now)
+		var samples []meterSample
+		if e.wantsMeterHistory() {
+			samples = e.meterHistorySamplesLocked()
+		}
+		e.mu.Unlock()
+		e.recordAlarmTransitions(trs)
+		if samples != nil {
+			e.recordMeterHistory(now, samples)
+		}
+	}
+}
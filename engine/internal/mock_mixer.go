@@ -0,0 +1,182 @@
+package app
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Mock mixer signal model (v0.3.66)
+//
+// The old mock meters were independent random walks, so moving the Host fader
+// or muting a mic had no visible effect. With mock.model=mixer (the default)
+// the mock engine models a small console instead:
+//
+//	source (synthetic) -> fader gain -> mute -> program bus (411/412) -> 131 mute
+//	program bus -> speaker level (160) -> mute (161) / automute (560) -> 460/461
+//	remote return (462/463) is an independent synthetic talker
+//
+// Sources:
+//   - mics (Host, Guest 1-3) and Zoom talk in bursts (talk/pause periods with
+//     syllable-rate modulation); the host talks most
+//   - CD1 plays a steady music bed; the other line sources are idle
+//
+// Fader taper: 0.75 is unity, 1.0 is +10 dB, 40 dB per full travel below
+// that, 0 is off. Channels sum by power (uncorrelated sources) and the bus
+// clips at full scale, so pushing faders drives the over-level alarm.
+//
+// Only channels whose fader RC is allowlisted are modeled; a missing mute RC
+// means "never muted". mock.model=random restores the old random walk.
+// Scenario keyframes (mock_scenario.go) still take precedence per meter.
+// ---------------------------------------------------------------------------
+
+const (
+	MockModelMixer  = "mixer"
+	MockModelRandom = "random"
+
+	mockFaderUnity = 0.75
+)
+
+// mockSource is one modeled console channel. Runtime state is guarded by e.mu.
+type mockSource struct {
+	name  string
+	fader int
+	mute  int
+	kind  string  // "speech" | "music" | "idle"
+	duty  float64 // fraction of time talking (speech)
+	level float64 // nominal amplitude when active (0..1 linear)
+
+	talking bool
+	until   time.Time
+	phase   float64
+}
+
+func newMockSources() []*mockSource {
+	return []*mockSource{
+		{name: "host", fader: 101, mute: 121, kind: "speech", duty: 0.6, level: 0.35},
+		{name: "g1", fader: 102, mute: 122, kind: "speech", duty: 0.25, level: 0.3},
+		{name: "g2", fader: 103, mute: 123, kind: "speech", duty: 0.2, level: 0.3},
+		{name: "g3", fader: 104, mute: 124, kind: "speech", duty: 0.15, level: 0.3},
+		{name: "cd1", fader: 105, mute: 125, kind: "music", level: 0.2},
+		{name: "cd2", fader: 106, mute: 126, kind: "idle"},
+		{name: "aux", fader: 107, mute: 127, kind: "idle"},
+		{name: "bt", fader: 108, mute: 128, kind: "idle"},
+		{name: "pc", fader: 109, mute: 129, kind: "idle"},
+		{name: "zoom", fader: 110, mute: 130, kind: "speech", duty: 0.2, level: 0.25},
+	}
+}
+
+const (
+	mockProgramMute = 131
+	mockSpeakerL    = 460
+	mockSpeakerR    = 461
+)
+
+// faderGain maps a fader position (0..1) to linear gain.
+func faderGain(pos float64) float64 {
+	if pos <= 0.001 {
+		return 0
+	}
+	return math.Pow(10, 40*(pos-mockFaderUnity)/20)
+}
+
+// mockMixer holds the model state. Guarded by e.mu.
+type mockMixer struct {
+	rng     *rand.Rand
+	sources []*mockSource
+	remote  *mockSource
+}
+
+func newMockMixer() *mockMixer {
+	return &mockMixer{
+		rng:     rand.New(rand.NewSource(time.Now().UnixNano())),
+		sources: newMockSources(),
+		remote:  &mockSource{name: "remote", kind: "speech", duty: 0.5, level: 0.3},
+	}
+}
+
+// sample returns the source's instantaneous amplitude (L, R).
+func (m *mockMixer) sample(s *mockSource, now time.Time, dt float64) (float64, float64) {
+	switch s.kind {
+	case "speech":
+		if now.After(s.until) {
+			if s.talking {
+				s.talking = m.rng.Float64() < s.duty*0.5
+			} else {
+				s.talking = m.rng.Float64() < s.duty
+			}
+			d := 0.5 + m.rng.Float64()*2.5
+			if s.talking {
+				d = 2 + m.rng.Float64()*6
+			}
+			s.until = now.Add(time.Duration(d * float64(time.Second)))
+		}
+		if !s.talking {
+			return 0, 0
+		}
+		// ~4 Hz syllable modulation plus some randomness.
+		s.phase += dt * 2 * math.Pi * (3 + m.rng.Float64()*2)
+		v := s.level * (0.35 + 0.65*math.Abs(math.Sin(s.phase))) * (0.8 + 0.4*m.rng.Float64())
+		return v, v * (0.97 + 0.06*m.rng.Float64())
+	case "music":
+		s.phase += dt * 2 * math.Pi * 0.2
+		base := s.level * (0.85 + 0.15*math.Sin(s.phase))
+		return base * (0.85 + 0.3*m.rng.Float64()), base * (0.85 + 0.3*m.rng.Float64())
+	default:
+		return 0, 0
+	}
+}
+
+// mockMixerStepLocked runs one model step and writes raw meter sources,
+// skipping meters a scenario is scripting. Caller MUST hold e.mu.
+func (e *Engine) mockMixerStepLocked(now time.Time, dt float64, scripted func(int) bool) {
+	m := e.mockMixer
+	var pl, pr float64 // program bus power
+	for _, s := range m.sources {
+		l, r := m.sample(s, now, dt)
+		if !e.allowed(s.fader) {
+			continue
+		}
+		if e.allowed(s.mute) && e.rc[s.mute] >= 0.5 {
+			continue
+		}
+		g := faderGain(e.rc[s.fader])
+		pl += (l * g) * (l * g)
+		pr += (r * g) * (r * g)
+	}
+	pgmL, pgmR := math.Min(1, math.Sqrt(pl)), math.Min(1, math.Sqrt(pr))
+	if e.allowed(mockProgramMute) && e.rc[mockProgramMute] >= 0.5 {
+		pgmL, pgmR = 0, 0
+	}
+
+	spkG := faderGain(e.rc[rcNameToID["STUB_SPK_LEVEL"]])
+	if e.rc[rcNameToID["STUB_SPK_MUTE"]] >= 0.5 || e.rc[rcNameToID["STUB_SPK_AUTOMUTE"]] >= 0.5 {
+		spkG = 0
+	}
+	rl, rr := m.sample(m.remote, now, dt)
+
+	out := map[int]float64{
+		rcNameToID["STUB_PGM_L"]: pgmL,
+		rcNameToID["STUB_PGM_R"]: pgmR,
+		mockSpeakerL:             math.Min(1, pgmL*spkG),
+		mockSpeakerR:             math.Min(1, pgmR*spkG),
+		rcNameToID["STUB_RSR_L"]: rl,
+		rcNameToID["STUB_RSR_R"]: rr,
+	}
+	for id, v := range out {
+		if !scripted(id) {
+			e.setMeterRawLocked(id, v)
+		}
+	}
+}
+
+// initMockMixerDefaultsLocked puts allowlisted faders at unity so the mock
+// studio starts "on air" instead of silent. Caller MUST hold e.mu.
+func (e *Engine) initMockMixerDefaultsLocked() {
+	for _, s := range e.mockMixer.sources {
+		if e.allowed(s.fader) {
+			e.rc[s.fader] = mockFaderUnity
+		}
+	}
+}
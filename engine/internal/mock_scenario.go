@@ -0,0 +1,421 @@
+package app
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ---------------------------------------------------------------------------
+// Scenario-driven mock mode (v0.3.65)
+//
+// mockLoop used to be a hard-coded random walk, so nobody could rehearse a
+// DSP outage, dead air or clipping without the real studio. In mock mode the
+// engine can now play a YAML scenario:
+//
+//	name: Dead air + DSP outage drill
+//	duration: 2m          # default: last keyframe / event
+//	loop: true
+//	seed: 7               # jitter is seeded, so runs are repeatable
+//	meters:               # keyframes in raw source units, linearly interpolated
+//	  STUB_PGM_L:
+//	    - {at: 0s,  level: 0.5, jitter: 0.05}
+//	    - {at: 30s, level: 0.5}
+//	    - {at: 31s, level: 0}
+//	events:
+//	  - {at: 10s, automute: true}
+//	  - {at: 40s, dsp: DISCONNECTED, error: "simulated outage"}
+//	  - {at: 50s, dsp: OK}
+//	  - {at: 70s, fail_writes: true, error: "simulated ECP timeout"}
+//	  - {at: 90s, fail_writes: false}
+//	  - {at: 95s, set: {STUB_SPK_LEVEL: 0.5}}
+//
+// Meters a scenario does not list keep the random walk. Simulated DSP health
+// goes through the normal health state machine, timeline and guard, and
+// simulated write failures are logged like real ones, so drills exercise the
+// same paths the live studio does. Nothing here ever talks to a real DSP.
+//
+// Scenarios live in <base>/scenarios (mock.scenario_dir); mock.scenario starts
+// one at boot, and admins can start/stop them via /api/mock/scenario.
+// ---------------------------------------------------------------------------
+
+// scenarioDuration accepts Go durations ("1m30s") or plain seconds in YAML.
+type scenarioDuration time.Duration
+
+func (d *scenarioDuration) UnmarshalYAML(n *yaml.Node) error {
+	var secs float64
+	if err := n.Decode(&secs); err == nil {
+		*d = scenarioDuration(secs * float64(time.Second))
+		return nil
+	}
+	var s string
+	if err := n.Decode(&s); err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(strings.TrimSpace(s))
+	if err != nil {
+		return fmt.Errorf("invalid duration %q", s)
+	}
+	*d = scenarioDuration(v)
+	return nil
+}
+
+// MockScenario is one parsed scenario file.
+type MockScenario struct {
+	Name     string                        `yaml:"name"`
+	Duration scenarioDuration              `yaml:"duration"`
+	Loop     bool                          `yaml:"loop"`
+	Seed     int64                         `yaml:"seed"`
+	Meters   map[string][]scenarioKeyframe `yaml:"meters"`
+	Events   []scenarioEvent               `yaml:"events"`
+
+	meters map[int][]scenarioKeyframe // resolved ids, sorted by At
+}
+
+type scenarioKeyframe struct {
+	At     scenarioDuration `yaml:"at"`
+	Level  float64          `yaml:"level"`
+	Jitter float64          `yaml:"jitter"`
+}
+
+type scenarioEvent struct {
+	At         scenarioDuration   `yaml:"at"`
+	Automute   *bool              `yaml:"automute"`
+	DSP        string             `yaml:"dsp"`
+	FailWrites *bool              `yaml:"fail_writes"`
+	Error      string             `yaml:"error"`
+	Set        map[string]float64 `yaml:"set"`
+}
+
+// LoadMockScenario reads and validates a scenario file.
+func LoadMockScenario(path string) (*MockScenario, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var sc MockScenario
+	if err := yaml.Unmarshal(b, &sc); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(sc.Name) == "" {
+		sc.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if sc.Seed == 0 {
+		sc.Seed = 1
+	}
+	end := time.Duration(0)
+	sc.meters = map[int][]scenarioKeyframe{}
+	for k, kfs := range sc.Meters {
+		id, err := resolveRC(k)
+		if err != nil || !isMeterRC(id) {
+			return nil, fmt.Errorf("meters: unknown meter %q", k)
+		}
+		if len(kfs) == 0 {
+			continue
+		}
+		sort.SliceStable(kfs, func(i, j int) bool { return kfs[i].At < kfs[j].At })
+		sc.meters[id] = kfs
+		end = max(end, time.Duration(kfs[len(kfs)-1].At))
+	}
+	for i, ev := range sc.Events {
+		ev.DSP = strings.ToUpper(strings.TrimSpace(ev.DSP))
+		switch DSPHealthState(ev.DSP) {
+		case "", DSPHealthOK, DSPHealthDegraded, DSPHealthDisconnected:
+		default:
+			return nil, fmt.Errorf("events[%d]: invalid dsp state %q", i, ev.DSP)
+		}
+		for k := range ev.Set {
+			id, err := resolveRC(k)
+			if err != nil || isMeterRC(id) {
+				return nil, fmt.Errorf("events[%d]: set: unknown control %q", i, k)
+			}
+		}
+		sc.Events[i] = ev
+		end = max(end, time.Duration(ev.At))
+	}
+	sort.SliceStable(sc.Events, func(i, j int) bool { return sc.Events[i].At < sc.Events[j].At })
+	if sc.Duration <= 0 {
+		sc.Duration = scenarioDuration(end)
+	}
+	if sc.Duration <= 0 {
+		return nil, fmt.Errorf("scenario has no keyframes or events")
+	}
+	return &sc, nil
+}
+
+// levelAt interpolates keyframes at offset t (holds before the first and
+// after the last keyframe).
+func levelAt(kfs []scenarioKeyframe, t time.Duration, rng *rand.Rand) float64 {
+	i := sort.Search(len(kfs), func(i int) bool { return time.Duration(kfs[i].At) > t })
+	var v, jitter float64
+	switch {
+	case i == 0:
+		v, jitter = kfs[0].Level, kfs[0].Jitter
+	case i == len(kfs):
+		v, jitter = kfs[i-1].Level, kfs[i-1].Jitter
+	default:
+		a, b := kfs[i-1], kfs[i]
+		f := float64(t-time.Duration(a.At)) / float64(b.At-a.At)
+		v = a.Level + (b.Level-a.Level)*f
+		jitter = a.Jitter + (b.Jitter-a.Jitter)*f
+	}
+	if jitter > 0 {
+		v += (rng.Float64()*2 - 1) * jitter
+	}
+	return math.Max(0, v)
+}
+
+// scenarioRun is the running scenario. Guarded by e.mu.
+type scenarioRun struct {
+	sc      *MockScenario
+	file    string
+	started time.Time
+	start   time.Time // start of the current pass
+	passes  int
+	next    int // index of the next event to fire
+	rng     *rand.Rand
+
+	failWrites bool
+	failErr    string
+}
+
+// ScenarioStatus is the public view served by GET /api/mock/scenario.
+type ScenarioStatus struct {
+	Ok         bool     `json:"ok"`
+	Running    bool     `json:"running"`
+	Name       string   `json:"name,omitempty"`
+	File       string   `json:"file,omitempty"`
+	StartedAt  string   `json:"startedAt,omitempty"`
+	ElapsedS   float64  `json:"elapsedS,omitempty"`
+	DurationS  float64  `json:"durationS,omitempty"`
+	Loop       bool     `json:"loop,omitempty"`
+	Passes     int      `json:"passes,omitempty"`
+	FailWrites bool     `json:"failWrites,omitempty"`
+	Dir        string   `json:"dir"`
+	Available  []string `json:"available"`
+}
+
+// scenarioDir returns the scenario library directory.
+func (e *Engine) scenarioDir() string {
+	if d := strings.TrimSpace(e.GetConfigCopy().Mock.ScenarioDir); d != "" {
+		return d
+	}
+	if strings.TrimSpace(e.stateDir) == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(e.stateDir), "scenarios")
+}
+
+// resolveScenarioFile maps a scenario name to a file inside the library.
+// Only bare file names are accepted so the API cannot read arbitrary files.
+func (e *Engine) resolveScenarioFile(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return "", fmt.Errorf("invalid scenario name %q", name)
+	}
+	dir := e.scenarioDir()
+	if dir == "" {
+		return "", fmt.Errorf("scenario directory is not configured")
+	}
+	for _, cand := range []string{name, name + ".yml", name + ".yaml"} {
+		p := filepath.Join(dir, cand)
+		if st, err := os.Stat(p); err == nil && !st.IsDir() {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("scenario %q not found in %s", name, dir)
+}
+
+func isMockMode(cfg Config) bool {
+	mode := strings.ToLower(strings.TrimSpace(cfg.DSP.Mode))
+	return mode == "mock" || mode == "simulate"
+}
+
+// StartScenario loads a scenario from the library and starts it from t=0,
+// replacing any running scenario. Mock mode only.
+func (e *Engine) StartScenario(name string) (ScenarioStatus, error) {
+	if !isMockMode(e.GetConfigCopy()) {
+		return ScenarioStatus{}, fmt.Errorf("scenarios are only available in mock mode")
+	}
+	path, err := e.resolveScenarioFile(name)
+	if err != nil {
+		return ScenarioStatus{}, err
+	}
+	return e.startScenarioFile(path)
+}
+
+func (e *Engine) startScenarioFile(path string) (ScenarioStatus, error) {
+	sc, err := LoadMockScenario(path)
+	if err != nil {
+		return ScenarioStatus{}, fmt.Errorf("scenario %s: %w", filepath.Base(path), err)
+	}
+	now := time.Now()
+	e.mu.Lock()
+	e.scenario = &scenarioRun{
+		sc:      sc,
+		file:    filepath.Base(path),
+		started: now,
+		start:   now,
+		rng:     rand.New(rand.NewSource(sc.Seed)),
+	}
+	e.mu.Unlock()
+	e.setSimulatedDSP("", "")
+	log.Printf("mock scenario started: %s (%s)", sc.Name, path)
+	e.PublishEvent("mock.scenario", map[string]any{"state": "started", "name": sc.Name, "file": filepath.Base(path)})
+	return e.ScenarioStatus(), nil
+}
+
+// StopScenario stops the running scenario (if any) and clears simulated faults.
+func (e *Engine) StopScenario() ScenarioStatus {
+	e.mu.Lock()
+	run := e.scenario
+	e.scenario = nil
+	e.mu.Unlock()
+	if run != nil {
+		e.setSimulatedDSP("", "")
+		e.PublishEvent("mock.scenario", map[string]any{"state": "stopped", "name": run.sc.Name})
+	}
+	return e.ScenarioStatus()
+}
+
+// stopScenarioUnlessMock stops a running scenario once the config leaves
+// mock mode, so scripted faults never touch live or shadow operation.
+func (e *Engine) stopScenarioUnlessMock() {
+	if !isMockMode(e.GetConfigCopy()) {
+		e.StopScenario()
+	}
+}
+
+// ScenarioStatus reports the running scenario and the library contents.
+func (e *Engine) ScenarioStatus() ScenarioStatus {
+	st := ScenarioStatus{Ok: true, Dir: e.scenarioDir(), Available: []string{}}
+	if st.Dir != "" {
+		for _, pat := range []string{"*.yml", "*.yaml"} {
+			m, _ := filepath.Glob(filepath.Join(st.Dir, pat))
+			for _, p := range m {
+				st.Available = append(st.Available, filepath.Base(p))
+			}
+		}
+		sort.Strings(st.Available)
+	}
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if run := e.scenario; run != nil {
+		st.Running = true
+		st.Name = run.sc.Name
+		st.File = run.file
+		st.StartedAt = run.started.UTC().Format(time.RFC3339)
+		st.ElapsedS = math.Round(time.Since(run.start).Seconds()*10) / 10
+		st.DurationS = time.Duration(run.sc.Duration).Seconds()
+		st.Loop = run.sc.Loop
+		st.Passes = run.passes
+		st.FailWrites = run.failWrites
+	}
+	return st
+}
+
+// scenarioTickLocked advances the running scenario: writes scripted meters and
+// returns the events now due (applied by the caller after unlocking) and
+// whether the scenario just finished. Caller MUST hold e.mu.
+func (e *Engine) scenarioTickLocked(now time.Time) (due []scenarioEvent, finished *scenarioRun) {
+	run := e.scenario
+	if run == nil {
+		return nil, nil
+	}
+	dur := time.Duration(run.sc.Duration)
+	t := now.Sub(run.start)
+	for ; run.next < len(run.sc.Events) && time.Duration(run.sc.Events[run.next].At) <= t; run.next++ {
+		due = append(due, run.sc.Events[run.next])
+	}
+	if t >= dur {
+		if !run.sc.Loop {
+			e.scenario = nil
+			return due, run
+		}
+		// Next pass starts clean: faults are re-applied by the script itself.
+		run.passes++
+		run.start = now
+		run.next = 0
+		run.failWrites, run.failErr = false, ""
+		run.rng = rand.New(rand.NewSource(run.sc.Seed))
+		t = 0
+		due = append(due, scenarioEvent{DSP: string(DSPHealthOK)})
+	}
+	for id, kfs := range run.sc.meters {
+		e.setMeterRawLocked(id, levelAt(kfs, t, run.rng))
+	}
+	for _, ev := range due {
+		if ev.Automute != nil && e.allowed(560) {
+			e.rc[rcNameToID["STUB_SPK_AUTOMUTE"]] = boolRC(*ev.Automute)
+		}
+		if ev.FailWrites != nil {
+			run.failWrites = *ev.FailWrites
+			run.failErr = ev.Error
+		}
+		for k, v := range ev.Set {
+			if id, err := resolveRC(k); err == nil && e.allowed(id) {
+				e.rc[id] = v
+			}
+		}
+	}
+	return due, nil
+}
+
+// applyScenarioEvents handles the parts of due events that need other locks.
+// Callers MUST NOT hold engine locks.
+func (e *Engine) applyScenarioEvents(due []scenarioEvent, finished *scenarioRun) {
+	for _, ev := range due {
+		if ev.DSP != "" {
+			e.setSimulatedDSP(DSPHealthState(ev.DSP), ev.Error)
+			e.TestDSPConnectivity(0)
+		}
+	}
+	if finished != nil {
+		e.setSimulatedDSP("", "")
+		e.TestDSPConnectivity(0)
+		log.Printf("mock scenario finished: %s", finished.sc.Name)
+		e.PublishEvent("mock.scenario", map[string]any{"state": "finished", "name": finished.sc.Name})
+	}
+}
+
+// setSimulatedDSP overrides the mock DSP health ("" clears the override).
+func (e *Engine) setSimulatedDSP(state DSPHealthState, errText string) {
+	e.ensureDSPHealthInit()
+	e.dspMu.Lock()
+	e.dsp.simState = state
+	e.dsp.simErr = errText
+	e.dspMu.Unlock()
+}
+
+// SimulatedWriteError returns the scripted write failure, if a scenario has
+// one active. Always nil outside mock mode.
+func (e *Engine) SimulatedWriteError() error {
+	if !isMockMode(e.GetConfigCopy()) {
+		return nil
+	}
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if run := e.scenario; run != nil && run.failWrites {
+		msg := run.failErr
+		if strings.TrimSpace(msg) == "" {
+			msg = "simulated write failure"
+		}
+		return fmt.Errorf("%s (scenario %s)", msg, run.sc.Name)
+	}
+	return nil
+}
+
+func boolRC(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -46,6 +46,19 @@ func RequestEngineRestart(reason string) error {
 	return os.WriteFile(p, b, 0644)
 }
 
+// RequestRestart is RequestEngineRestart plus a journal entry (v0.3.70).
+func (e *Engine) RequestRestart(reason string) error {
+	err := RequestEngineRestart(reason)
+	details := map[string]any{"reason": strings.TrimSpace(reason)}
+	if err != nil {
+		details["error"] = err.Error()
+		e.RecordEvent("engine.restart_requested", SeverityError, details)
+		return err
+	}
+	e.RecordEvent("engine.restart_requested", SeverityWarn, details)
+	return nil
+}
+
 // RestartRequired returns true if a restart has been requested.
 func RestartRequired() bool {
 	p, err := RestartFlagPath()
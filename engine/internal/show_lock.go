@@ -0,0 +1,279 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Show lock (v0.3.75)
+//
+// During a live show some controls (speaker mute, program-affecting levels)
+// must not change because someone brushed a touch screen. A show lock
+// freezes a set of controls server-side:
+//
+//	POST /api/show/lock    {"reason": "morning show",
+//	                        "groups": ["speaker", "mics"],
+//	                        "controls": ["101"],
+//	                        "override": ["board", "role:admin"]}
+//	POST /api/show/unlock  {"reason": "off air"}
+//	GET  /api/show/lock
+//
+// Controls are RC ids or STUB_* names; groups come from show_lock.groups
+// (default speaker / mics / faders) and "all" freezes every control.
+//
+// Override entries are caller names (session / token name) or "role:<role>"
+// (that role or higher). Without an override list the locker and admins may
+// override. Everyone else gets 423 Locked with the lock reason from
+// /api/rc/<id> and the intent endpoints; DSPControlAllowed refuses too, so
+// any other write path is covered.
+//
+// Locking takes at least the operator role; unlocking (or replacing a lock)
+// takes an override. The lock survives engine restarts
+// (state/show_lock.json). Lock, unlock and refused writes are audited in
+// state/intents.jsonl and journaled / broadcast as "show.lock" and
+// "show.blocked".
+// ---------------------------------------------------------------------------
+
+// ShowLockAll is the group that freezes every control.
+const ShowLockAll = "all"
+
+// ShowLock is the current show lock.
+type ShowLock struct {
+	Locked   bool     `json:"locked"`
+	Reason   string   `json:"reason,omitempty"`
+	Groups   []string `json:"groups,omitempty"`
+	Controls []string `json:"controls,omitempty"`
+	// RCs are the frozen RC ids (groups expanded); empty with group "all".
+	RCs      []int    `json:"rcs,omitempty"`
+	Override []string `json:"override,omitempty"`
+	LockedBy string   `json:"lockedBy,omitempty"`
+	LockedAt string   `json:"lockedAt,omitempty"`
+}
+
+// ShowLockRequest is the body of POST /api/show/lock.
+type ShowLockRequest struct {
+	Reason   string   `json:"reason"`
+	Groups   []string `json:"groups"`
+	Controls []string `json:"controls"`
+	Override []string `json:"override"`
+}
+
+// ShowLockError is returned for a write to a frozen control, or (Control
+// "") for changing the lock without an override.
+type ShowLockError struct {
+	Control string
+	Lock    ShowLock
+}
+
+func (err *ShowLockError) Error() string {
+	by := err.Lock.LockedBy
+	if by == "" {
+		by = "unknown"
+	}
+	if err.Control == "" {
+		return fmt.Sprintf("show lock is held (%s; locked by %s); only %s may change it", err.Lock.Reason, by, strings.Join(err.Lock.Override, ", "))
+	}
+	return fmt.Sprintf("show lock: %s is frozen (%s; locked by %s)", err.Control, err.Lock.Reason, by)
+}
+
+func defaultShowLockGroups() map[string][]string {
+	faders := []string{}
+	for id := 101; id <= 110; id++ {
+		faders = append(faders, itoa(id))
+	}
+	return map[string][]string{
+		"speaker": {"STUB_SPK_LEVEL", "STUB_SPK_MUTE"},
+		"mics":    {"STUB_MIC_HOST", "STUB_MIC_GUEST_1", "STUB_MIC_GUEST_2", "STUB_MIC_GUEST_3"},
+		"faders":  faders,
+	}
+}
+
+func showLockPath(stateDir string) string {
+	if strings.TrimSpace(stateDir) == "" {
+		return ""
+	}
+	return filepath.Join(stateDir, "show_lock.json")
+}
+
+// loadShowLock restores a lock saved before a restart.
+func loadShowLock(stateDir string) ShowLock {
+	var l ShowLock
+	if p := showLockPath(stateDir); p != "" {
+		if b, err := os.ReadFile(p); err == nil {
+			_ = json.Unmarshal(b, &l)
+		}
+	}
+	return l
+}
+
+func (e *Engine) saveShowLockLocked() {
+	p := showLockPath(e.stateDir)
+	if p == "" {
+		return
+	}
+	_ = os.MkdirAll(filepath.Dir(p), 0755)
+	b, _ := json.MarshalIndent(e.showLock, "", "  ")
+	_ = os.WriteFile(p, b, 0644)
+}
+
+// ShowLockStatus returns the current lock.
+func (e *Engine) ShowLockStatus() ShowLock {
+	e.showLockMu.Lock()
+	defer e.showLockMu.Unlock()
+	return e.showLock
+}
+
+// mayOverride reports whether who may write through (or lift) lock l.
+func (l ShowLock) mayOverride(who Identity) bool {
+	if roleRank(who.Role) == 0 {
+		return false
+	}
+	for _, o := range l.Override {
+		if role, ok := strings.CutPrefix(o, "role:"); ok {
+			if RoleAtLeast(who.Role, role) {
+				return true
+			}
+		} else if who.Name != "" && strings.EqualFold(o, who.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (l ShowLock) freezes(rc int) bool {
+	for _, g := range l.Groups {
+		if g == ShowLockAll {
+			return true
+		}
+	}
+	for _, id := range l.RCs {
+		if id == rc {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckShowLock returns a *ShowLockError when ctl (RC id or name) is frozen
+// and who may not override. Refusals are audited and journaled.
+func (e *Engine) CheckShowLock(ctl string, who Identity) error {
+	rc, err := resolveRC(strings.TrimSpace(ctl))
+	if err != nil {
+		return nil // not a control; the write path rejects it
+	}
+	e.showLockMu.Lock()
+	l := e.showLock
+	e.showLockMu.Unlock()
+	if !l.Locked || !l.freezes(rc) || l.mayOverride(who) {
+		return nil
+	}
+	details := map[string]any{"control": ctl, "rc": rc, "reason": l.Reason, "caller": who.Name, "role": who.Role}
+	_ = e.appendIntent(IntentEvent{Action: "show.blocked", Source: who.Name, Details: details})
+	e.RecordEvent("show.blocked", SeverityWarn, details)
+	return &ShowLockError{Control: ctl, Lock: l}
+}
+
+// LockShow freezes the requested controls and groups.
+func (e *Engine) LockShow(req ShowLockRequest, who Identity) (ShowLock, error) {
+	if !RoleAtLeast(who.Role, RoleOperator) {
+		return ShowLock{}, fmt.Errorf("show lock requires the operator role")
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return ShowLock{}, fmt.Errorf("a reason is required")
+	}
+	cfg := e.GetConfigCopy()
+	l := ShowLock{Locked: true, Reason: reason, LockedBy: who.Name, LockedAt: time.Now().UTC().Format(time.RFC3339)}
+	seen := map[int]bool{}
+	add := func(ctl string) error {
+		rc, err := resolveRC(strings.TrimSpace(ctl))
+		if err != nil {
+			return fmt.Errorf("unknown control %q", ctl)
+		}
+		if !seen[rc] {
+			seen[rc] = true
+			l.RCs = append(l.RCs, rc)
+		}
+		return nil
+	}
+	for _, g := range req.Groups {
+		g = strings.ToLower(strings.TrimSpace(g))
+		if g == ShowLockAll {
+			l.Groups = append(l.Groups, g)
+			continue
+		}
+		ctls, ok := cfg.ShowLock.Groups[g]
+		if !ok {
+			return ShowLock{}, fmt.Errorf("unknown group %q", g)
+		}
+		l.Groups = append(l.Groups, g)
+		for _, c := range ctls {
+			if err := add(c); err != nil {
+				return ShowLock{}, err
+			}
+		}
+	}
+	for _, c := range req.Controls {
+		if err := add(c); err != nil {
+			return ShowLock{}, err
+		}
+		l.Controls = append(l.Controls, strings.TrimSpace(c))
+	}
+	if len(l.Groups) == 0 && len(l.RCs) == 0 {
+		return ShowLock{}, fmt.Errorf("name at least one control or group")
+	}
+	sort.Ints(l.RCs)
+	for _, o := range req.Override {
+		if o = strings.TrimSpace(o); o != "" {
+			l.Override = append(l.Override, o)
+		}
+	}
+	if len(l.Override) == 0 {
+		if who.Name != "" {
+			l.Override = append(l.Override, who.Name)
+		}
+		l.Override = append(l.Override, "role:"+RoleAdmin)
+	}
+
+	e.showLockMu.Lock()
+	if prev := e.showLock; prev.Locked && !prev.mayOverride(who) {
+		e.showLockMu.Unlock()
+		return ShowLock{}, &ShowLockError{Lock: prev}
+	}
+	e.showLock = l
+	e.saveShowLockLocked()
+	e.showLockMu.Unlock()
+
+	details := map[string]any{"locked": true, "reason": reason, "groups": l.Groups, "rcs": l.RCs, "override": l.Override, "by": who.Name}
+	_ = e.appendIntent(IntentEvent{Action: "show.lock", Source: who.Name, Details: details})
+	e.RecordEvent("show.lock", SeverityWarn, details)
+	return l, nil
+}
+
+// UnlockShow lifts the lock; who must be allowed to override it.
+func (e *Engine) UnlockShow(reason string, who Identity) (ShowLock, error) {
+	e.showLockMu.Lock()
+	prev := e.showLock
+	if !prev.Locked {
+		e.showLockMu.Unlock()
+		return prev, nil
+	}
+	if !prev.mayOverride(who) {
+		e.showLockMu.Unlock()
+		return prev, &ShowLockError{Lock: prev}
+	}
+	e.showLock = ShowLock{}
+	e.saveShowLockLocked()
+	e.showLockMu.Unlock()
+
+	details := map[string]any{"locked": false, "reason": strings.TrimSpace(reason), "was": prev.Reason, "by": who.Name}
+	_ = e.appendIntent(IntentEvent{Action: "show.unlock", Source: who.Name, Details: details})
+	e.RecordEvent("show.lock", SeverityInfo, details)
+	return ShowLock{}, nil
+}
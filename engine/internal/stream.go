@@ -0,0 +1,259 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Stream topics + Server-Sent Events (v0.3.58)
+//
+// /ws and /api/stream share one message model:
+//
+//	snapshot  full RC map (sent on connect)
+//	delta     { "type": "delta", "rc": {...}, "t": <ms> }
+//	event     { "type": "event", "kind": "...", "ts": "...", "details": {...} }
+//
+// Messages belong to a topic so light consumers can ask only for what they need:
+//
+//	controls  RC deltas for faders, mutes, indicators
+//	meters    RC deltas for meters (the 20 Hz firehose)
+//	events    discrete runtime events (DSP health changes, config reloads, ...)
+//
+// Filtering: ?topics=controls,events (default: all topics).
+//
+// SSE resume:
+//   - Every published message gets a monotonically increasing sequence number.
+//   - The SSE "id:" is "<boot>:<seq>" so IDs from a previous engine process are
+//     never mistaken for current ones.
+//   - A reconnecting client sends Last-Event-ID (browsers do this automatically;
+//     curl users may pass ?lastEventId=). If the ID is still inside the replay
+//     ring, we replay what was missed; otherwise we send a fresh snapshot.
+// ---------------------------------------------------------------------------
+
+const (
+	TopicControls = "controls"
+	TopicMeters   = "meters"
+	TopicEvents   = "events"
+
+	// streamRingSize bounds the replay buffer (~100 s of meter ticks at 20 Hz).
+	streamRingSize = 2048
+	// streamSubBuffer is the per-subscriber queue. A subscriber that falls this
+	// far behind is dropped; it reconnects and resumes via Last-Event-ID.
+	streamSubBuffer = 256
+)
+
+var allTopics = []string{TopicControls, TopicMeters, TopicEvents}
+
+// streamMsg is one published message, pre-encoded once for all consumers.
+type streamMsg struct {
+	Seq   uint64
+	Topic string
+	Type  string
+	Data  []byte
+}
+
+// streamHub is stored on Engine and guarded by its own mutex.
+type streamHub struct {
+	mu   sync.Mutex
+	boot string
+	seq  uint64
+	ring []streamMsg
+	subs map[chan streamMsg]map[string]bool
+}
+
+func newStreamHub() *streamHub {
+	return &streamHub{
+		boot: strconv.FormatInt(time.Now().UnixMilli(), 36),
+		subs: make(map[chan streamMsg]map[string]bool),
+	}
+}
+
+// parseTopics reads ?topics=a,b (or repeated ?topic=a&topic=b).
+// nil means "all topics". Unknown names are ignored.
+func parseTopics(r *http.Request) map[string]bool {
+	q := r.URL.Query()
+	var raw []string
+	for _, v := range append(q["topics"], q["topic"]...) {
+		raw = append(raw, strings.Split(v, ",")...)
+	}
+	var out map[string]bool
+	for _, t := range raw {
+		t = strings.ToLower(strings.TrimSpace(t))
+		for _, known := range allTopics {
+			if t == known {
+				if out == nil {
+					out = map[string]bool{}
+				}
+				out[t] = true
+			}
+		}
+	}
+	return out
+}
+
+// wantsTopic reports whether a topic filter (nil == all) includes topic.
+func wantsTopic(topics map[string]bool, topic string) bool {
+	return topics == nil || topics[topic]
+}
+
+// streamPublish assigns the next sequence number, stores the message in the
+// replay ring and fans it out to SSE subscribers.
+func (e *Engine) streamPublish(topic string, payload map[string]any) {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+	typ, _ := payload["type"].(string)
+
+	h := e.stream
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.seq++
+	m := streamMsg{Seq: h.seq, Topic: topic, Type: typ, Data: b}
+	h.ring = append(h.ring, m)
+	if len(h.ring) > streamRingSize {
+		h.ring = h.ring[len(h.ring)-streamRingSize:]
+	}
+	for ch, topics := range h.subs {
+		if !wantsTopic(topics, topic) {
+			continue
+		}
+		select {
+		case ch <- m:
+		default:
+			// Slow consumer: drop it rather than block the publisher.
+			delete(h.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// PublishEvent sends a discrete runtime event on the events topic to both
+// /ws clients and /api/stream subscribers.
+//
+// Events are small and infrequent. Callers MUST NOT hold engine locks
+// (WebSocket writes may block for up to the write deadline).
+//
+// v0.3.70: this is the info-severity form of RecordEvent (journal.go), so
+// every published event is also journaled.
+func (e *Engine) PublishEvent(kind string, details map[string]any) {
+	e.RecordEvent(kind, SeverityInfo, details)
+}
+
+// HandleSSE serves GET /api/stream as text/event-stream.
+func (e *Engine) HandleSSE(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "GET required", http.StatusMethodNotAllowed)
+		return
+	}
+	fl, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	// Read-only stream, but still subject to auth.anonymous_role=none.
+	if _, err := e.ResolveIdentity(r); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	topics := parseTopics(r)
+	lastID := strings.TrimSpace(r.Header.Get("Last-Event-ID"))
+	if lastID == "" {
+		lastID = strings.TrimSpace(r.URL.Query().Get("lastEventId"))
+	}
+
+	// Subscribe first, then decide between replay and snapshot, all under the
+	// hub lock so no message can slip between the two.
+	h := e.stream
+	ch := make(chan streamMsg, streamSubBuffer)
+	h.mu.Lock()
+	h.subs[ch] = topics
+	seq := h.seq
+	boot := h.boot
+	var replay []streamMsg
+	resumed := false
+	if from, ok := parseStreamID(lastID, boot); ok && from <= seq {
+		if from == seq {
+			resumed = true
+		} else if len(h.ring) > 0 && h.ring[0].Seq <= from+1 {
+			resumed = true
+			for _, m := range h.ring {
+				if m.Seq > from && wantsTopic(topics, m.Topic) {
+					replay = append(replay, m)
+				}
+			}
+		}
+	}
+	h.mu.Unlock()
+
+	defer func() {
+		h.mu.Lock()
+		if _, still := h.subs[ch]; still {
+			delete(h.subs, ch)
+			close(ch)
+		}
+		h.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// nginx: do not buffer the stream.
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	// Tell EventSource how long to wait before reconnecting.
+	fmt.Fprint(w, "retry: 2000\n\n")
+	if !resumed {
+		b, _ := json.Marshal(map[string]any{"type": "snapshot", "data": e.StateSnapshot()})
+		writeSSE(w, boot, seq, "snapshot", b)
+	}
+	for _, m := range replay {
+		writeSSE(w, boot, m.Seq, m.Type, m.Data)
+	}
+	fl.Flush()
+
+	keepalive := time.NewTicker(15 * time.Second)
+	defer keepalive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case m, ok := <-ch:
+			if !ok {
+				log.Printf("sse: dropping slow subscriber %s", r.RemoteAddr)
+				return
+			}
+			writeSSE(w, boot, m.Seq, m.Type, m.Data)
+			fl.Flush()
+		case <-keepalive.C:
+			// Comment line keeps proxies from timing out an idle stream.
+			fmt.Fprint(w, ": keepalive\n\n")
+			fl.Flush()
+		}
+	}
+}
+
+func writeSSE(w http.ResponseWriter, boot string, seq uint64, event string, data []byte) {
+	fmt.Fprintf(w, "id: %s:%d\nevent: %s\ndata: %s\n\n", boot, seq, event, data)
+}
+
+// parseStreamID parses "<boot>:<seq>". IDs from another boot are rejected.
+func parseStreamID(id, boot string) (uint64, bool) {
+	b, s, ok := strings.Cut(id, ":")
+	if !ok || b != boot {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
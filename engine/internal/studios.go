@@ -0,0 +1,146 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ---------------------------------------------------------------------------
+// Multi-studio engine (v0.3.72)
+//
+// One engine process can host several rooms. The studio in config.v1 is the
+// default studio and keeps every existing path (/api/..., /ws). Each entry
+// in `studios:` is an additional room with its own config file:
+//
+//	studios:
+//	  - name: studio-a
+//	    config: studio-a.v1      # relative to this config's directory
+//	  - name: prod
+//	    config: /etc/studiob/prod.v1
+//
+// Each additional studio is a separate Engine: its own DSP endpoint(s), RC
+// allowlist (control registry), mode, health monitor, meters/alarms, intent
+// log and event journal. State goes to <state>/studios/<name>/, where
+// <state> is the default studio's state directory (wherever the studio's
+// config file lives). Its API and WebSocket / SSE topics are served under
+// /studios/<name>/ (e.g. /studios/prod/api/state, /studios/prod/ws).
+// Sessions are per studio too: a studio's cookie is studiob_session_<name>
+// with Path /studios/<name>/, so logging in to one room never replaces the
+// default studio's session (see sessionCookieScope).
+//
+// Process-wide concerns stay with the default studio: HTTP listen address,
+// updates, releases, restart, watchdog, the config file editor and the PIL
+// proxy. A studio config's ui.http_listen and studios: are ignored, and the
+// config.json / STUDIOB_* overrides apply to the default studio only.
+// Adding or removing studios takes an engine restart.
+// ---------------------------------------------------------------------------
+
+// StudioRef names an additional studio and its config file.
+type StudioRef struct {
+	Name   string `yaml:"name"`
+	Config string `yaml:"config"`
+}
+
+var studioNameRe = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// Studio is one loaded additional studio.
+type Studio struct {
+	Name   string
+	Engine *Engine
+	Config *Config
+}
+
+// StudioSummary is one row of GET /api/studios.
+type StudioSummary struct {
+	Name      string         `json:"name"`
+	Default   bool           `json:"default"`
+	Prefix    string         `json:"prefix"` // "" for the default studio
+	Mode      string         `json:"mode"`
+	DSPHost   string         `json:"dspHost"`
+	DSPPort   int            `json:"dspPort"`
+	DSPHealth DSPHealthState `json:"dspHealth"`
+	Alarms    int            `json:"activeAlarms"`
+}
+
+// Studio returns the engine's studio name ("" for the default studio).
+func (e *Engine) Studio() string { return e.studio }
+
+// loadOwnConfig (re)loads this engine's config file the way it was loaded at
+// startup.
+func (e *Engine) loadOwnConfig(path string) (*Config, error) {
+	if e.studio != "" {
+		return LoadStudioConfig(path)
+	}
+	return LoadConfig(path)
+}
+
+// LoadStudios loads and starts every studio listed in the default engine's
+// config. A studio that fails to load is skipped with an error; the others
+// still start.
+func (e *Engine) LoadStudios() ([]*Studio, []error) {
+	cfg := e.GetConfigCopy()
+	cfgPath := e.cfgPath
+	var out []*Studio
+	var errs []error
+	seen := map[string]bool{}
+	for _, ref := range cfg.Studios {
+		name := strings.ToLower(strings.TrimSpace(ref.Name))
+		if !studioNameRe.MatchString(name) {
+			errs = append(errs, fmt.Errorf("studio %q: name must be lowercase letters, digits, - or _", ref.Name))
+			continue
+		}
+		if seen[name] {
+			errs = append(errs, fmt.Errorf("studio %q: duplicate name", name))
+			continue
+		}
+		seen[name] = true
+		p := strings.TrimSpace(ref.Config)
+		if p == "" {
+			errs = append(errs, fmt.Errorf("studio %q: config path is empty", name))
+			continue
+		}
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(filepath.Dir(cfgPath), p)
+		}
+		scfg, err := LoadStudioConfig(p)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("studio %q: %v", name, err))
+			continue
+		}
+		out = append(out, &Studio{Name: name, Engine: newEngine(scfg, e.version, p, name, e.stateDir), Config: scfg})
+	}
+	return out, errs
+}
+
+// StudioSummary describes this engine for GET /api/studios.
+func (e *Engine) StudioSummary() StudioSummary {
+	cfg := e.GetConfigCopy()
+	s := StudioSummary{
+		Name:      e.studio,
+		Default:   e.studio == "",
+		Mode:      cfg.DSP.Mode,
+		DSPHost:   cfg.DSP.Host,
+		DSPPort:   cfg.DSP.Port,
+		DSPHealth: e.DSPHealthSnapshot().State,
+		Alarms:    len(e.ActiveAlarms()),
+	}
+	if s.Default {
+		s.Name = "default"
+	} else {
+		s.Prefix = "/studios/" + e.studio
+	}
+	return s
+}
+
+// StudioGlobalPath reports whether an API path is process-wide and therefore
+// served only by the default studio.
+func StudioGlobalPath(p string) bool {
+	for _, pre := range []string{"/api/admin/", "/api/updates/", "/api/update/", "/api/pil/", "/api/watchdog/"} {
+		if strings.HasPrefix(p, pre) {
+			return true
+		}
+	}
+	return false
+}
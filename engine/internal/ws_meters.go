@@ -0,0 +1,204 @@
+package app
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// ---------------------------------------------------------------------------
+// Compact binary meter frames (v0.3.57)
+//
+// Meter deltas used to go out as JSON maps keyed by RC id, 20 times a second,
+// to every tablet. That is a lot of string formatting and parsing for six
+// numbers. Clients may now opt in to a binary meter stream:
+//
+//	/ws?meters=u16   levels packed as uint16 (0..65535 == 0.0..1.0)
+//	/ws?meters=f32   levels packed as float32
+//
+// Opted-in clients receive one JSON "meter_index" message right after the
+// snapshot. It lists the meter RC ids in the order they appear in every
+// binary frame; it is sent again, before the next frame, whenever a config
+// reload changes the allowlisted meters. After that, meters arrive ONLY as binary frames; control
+// RCs (faders, mutes, ...) keep arriving as JSON "delta" messages.
+//
+// Binary frame layout (little endian):
+//
+//	byte 0     magic 'M'
+//	byte 1     encoding (1 = u16, 2 = f32)
+//	bytes 2-3  count (uint16)
+//	bytes 4-11 time (uint64, unix millis)
+//	then count levels in meter_index order (2 or 4 bytes each)
+//	then count held peaks in the same order (v0.3.60, "peaks": true)
+//	then count level dBFS, then count peak dBFS (v0.3.61); u16 frames pack
+//	these as signed int16 centi-dB (-1234 == -12.34 dBFS), f32 as float32
+//
+// meter_index.sections names the sections in order so clients can skip
+// the ones they do not need.
+//
+// Clients that do not pass ?meters= keep the exact legacy JSON behavior.
+// ---------------------------------------------------------------------------
+
+// meterRCIDs is the fixed order of the meter index table.
+// meters: 411/412 program, 460/461 speakers, 462/463 remote return
+var meterRCIDs = []int{411, 412, 460, 461, 462, 463}
+
+const (
+	meterFrameMagic = 'M'
+	meterEncU16     = 1
+	meterEncF32     = 2
+
+	meterFrameHeaderLen = 12
+)
+
+func isMeterRC(id int) bool {
+	for _, m := range meterRCIDs {
+		if m == id {
+			return true
+		}
+	}
+	return false
+}
+
+// wsClient is one connected /ws socket.
+//
+// gorilla/websocket allows only one concurrent writer per connection, so every
+// write goes through writeMu (snapshot on connect and the publish loop can
+// otherwise race).
+type wsClient struct {
+	conn *websocket.Conn
+	// meterEnc is 0 for legacy JSON meters, or meterEncU16 / meterEncF32.
+	meterEnc byte
+	// topics is the ?topics= filter (nil == all topics).
+	topics map[string]bool
+	// id is the role this socket was bound to at handshake time.
+	// Only operator/admin sockets may ever carry control commands.
+	id Identity
+
+	writeMu sync.Mutex
+}
+
+// parseMeterEncoding maps the ?meters= query value to a frame encoding.
+// Unknown values fall back to legacy JSON (0) so old clients never break.
+func parseMeterEncoding(r *http.Request) byte {
+	switch strings.ToLower(strings.TrimSpace(r.URL.Query().Get("meters"))) {
+	case "u16", "uint16", "binary":
+		return meterEncU16
+	case "f32", "float32":
+		return meterEncF32
+	default:
+		return 0
+	}
+}
+
+func meterEncodingName(enc byte) string {
+	switch enc {
+	case meterEncU16:
+		return "u16"
+	case meterEncF32:
+		return "f32"
+	default:
+		return "json"
+	}
+}
+
+// writeText sends a JSON text frame. permessage-deflate (when negotiated) is
+// enabled for JSON because it compresses well.
+func (c *wsClient) writeText(b []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.writeTextLocked(b)
+}
+
+// writeTextLocked is writeText for a caller that holds c.writeMu.
+func (c *wsClient) writeTextLocked(b []byte) error {
+	c.conn.EnableWriteCompression(true)
+	_ = c.conn.SetWriteDeadline(time.Now().Add(2 * time.Second))
+	return c.conn.WriteMessage(websocket.TextMessage, b)
+}
+
+// writeBinary sends a binary frame without compression; packed levels do
+// not deflate well and compressing them only costs CPU on both ends.
+func (c *wsClient) writeBinary(b []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	c.conn.EnableWriteCompression(false)
+	_ = c.conn.SetWriteDeadline(time.Now().Add(2 * time.Second))
+	return c.conn.WriteMessage(websocket.BinaryMessage, b)
+}
+
+// meterIndex returns the allowlisted meter ids in frame order.
+func (e *Engine) meterIndex() []int {
+	out := make([]int, 0, len(meterRCIDs))
+	for _, id := range meterRCIDs {
+		if e.allowed(id) {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// meterIndexMessage is the JSON "meter_index" message for a binary client.
+func meterIndexMessage(enc byte, index []int) []byte {
+	b, _ := json.Marshal(map[string]any{
+		"type":     "meter_index",
+		"encoding": meterEncodingName(enc),
+		"ids":      index,
+		"peaks":    true,
+		"sections": []string{"level", "peak", "levelDb", "peakDb"},
+	})
+	return b
+}
+
+// broadcastMeterIndex re-sends meter_index to every binary meter client.
+func (e *Engine) broadcastMeterIndex(index []int) {
+	e.clientsMu.Lock()
+	defer e.clientsMu.Unlock()
+	for _, cl := range e.clients {
+		if cl.meterEnc != 0 && wantsTopic(cl.topics, TopicMeters) {
+			_ = cl.writeText(meterIndexMessage(cl.meterEnc, index))
+		}
+	}
+}
+
+// encodeMeterFrame packs values into a binary frame. values holds four
+// sections of equal length in meter index order: levels and peaks
+// (normalized), then level and peak dBFS.
+func encodeMeterFrame(enc byte, values []float64, t time.Time) []byte {
+	width := 2
+	if enc == meterEncF32 {
+		width = 4
+	}
+	n := len(values) / 4
+	b := make([]byte, meterFrameHeaderLen+len(values)*width)
+	b[0] = meterFrameMagic
+	b[1] = enc
+	binary.LittleEndian.PutUint16(b[2:4], uint16(n))
+	binary.LittleEndian.PutUint64(b[4:12], uint64(t.UnixMilli()))
+	off := meterFrameHeaderLen
+	for i, v := range values {
+		if math.IsNaN(v) {
+			v = 0
+		}
+		isDB := i >= 2*n
+		switch {
+		case enc == meterEncF32:
+			binary.LittleEndian.PutUint32(b[off:], math.Float32bits(float32(v)))
+		case isDB:
+			cdb := math.Round(v * 100)
+			cdb = math.Max(math.MinInt16, math.Min(math.MaxInt16, cdb))
+			binary.LittleEndian.PutUint16(b[off:], uint16(int16(cdb)))
+		default:
+			v = math.Max(0, math.Min(1, v))
+			binary.LittleEndian.PutUint16(b[off:], uint16(math.Round(v*65535)))
+		}
+		off += width
+	}
+	return b
+}
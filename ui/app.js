@@ -46,6 +46,10 @@ const state = {
     rsrL: { cur: 0, tgt: 0 },
     rsrR: { cur: 0, tgt: 0 },
   },
+  // Calibrated meter levels and scale floors in dBFS (engine v0.3.61),
+  // keyed like meters (pgmL, ...). The lane bars and -12/0 marks use these.
+  meterDb: {},
+  meterFloorDb: {},
   speaker: { level: 0, mute: false, automute: false },
 
   // Persisted-vs-runtime clarity (UI v0.3.07)
@@ -74,6 +78,11 @@ const state = {
   // - In-memory only (does not write to disk)
   // - Bounded size (prevents unbounded growth)
   // - Best-effort: we only log what we can observe from existing endpoints.
+  //
+  // v0.3.70: the engine keeps its own event journal (/api/events). On load we
+  // seed this list from it and then append live "event" messages from /ws, so
+  // engine-side history survives a browser reload. Those lines are tagged
+  // "[engine]".
   runtimeEvents: {
     max: 20,
     items: [], // { t: "HH:MM:SS", msg: string }
@@ -141,7 +150,7 @@ function _hhmmss(){
   return `${hh}:${mm}:${ss}`;
 }
 
-function addRuntimeEvent(msg){
+function addRuntimeEvent(msg, t){
   try{
     if(!msg) return;
     const ev = state.runtimeEvents;
@@ -151,7 +160,7 @@ function addRuntimeEvent(msg){
     const last = ev.items.length ? ev.items[ev.items.length - 1] : null;
     if(last && last.msg === msg) return;
 
-    ev.items.push({ t: _hhmmss(), msg: String(msg) });
+    ev.items.push({ t: t || _hhmmss(), msg: String(msg) });
     // Keep a strict bound.
     while(ev.items.length > ev.max) ev.items.shift();
 
@@ -161,6 +170,38 @@ function addRuntimeEvent(msg){
   }
 }
 
+// Engine journal entries (v0.3.70). Accepts both /api/events entries and
+// /ws "event" messages (kind/ts instead of type/time).
+function addEngineEvent(ev){
+  if(!ev) return;
+  const kind = ev.kind || ev.type || "event";
+  const d = ev.details || {};
+  let text = kind;
+  if(kind === "dsp.health") text = `DSP health ${d.from} → ${d.to}${d.reason ? ` (${d.reason})` : ""}`;
+  else if(kind.startsWith("alarm.")) text = `${kind.slice(6)} alarm ${d.state || ""} (${d.group || "?"})`;
+  else if(kind === "dsp.arm") text = d.armed ? `Live writes armed${d.reason ? ` (${d.reason}, ${d.ttlSec}s)` : ""}` : `Live writes disarmed${d.reason ? `: ${d.reason}` : ""}${d.error ? `: ${d.error}` : ""}`;
+  else if(kind === "show.lock") text = d.locked ? `Show lock on (${d.reason}${d.by ? `, ${d.by}` : ""})` : `Show lock off${d.by ? ` (${d.by})` : ""}`;
+  else if(kind === "control.rejected") text = `Write refused (rc ${d.rc}, ${d.rule}): ${d.error || ""}`;
+  else if(kind === "show.blocked") text = `Show lock refused ${d.control}${d.caller ? ` from ${d.caller}` : ""}`;
+  else if(d.state || d.error) text = `${kind} ${d.state || ""}${d.error ? `: ${d.error}` : ""}`;
+  const sev = (ev.severity && ev.severity !== "info") ? ` ${ev.severity.toUpperCase()}` : "";
+  let t = null;
+  const ts = new Date(ev.ts || ev.time || "");
+  if(!isNaN(ts)){
+    t = [ts.getHours(), ts.getMinutes(), ts.getSeconds()].map(v => String(v).padStart(2, "0")).join(":");
+  }
+  addRuntimeEvent(`[engine${sev}] ${text.trim()}`, t);
+}
+
+async function seedRuntimeEventsFromJournal(){
+  try{
+    const j = await fetchJSON(`/api/events?n=${state.runtimeEvents.max}`, { cache: "no-store" }, 2500);
+    (j?.events || []).forEach(addEngineEvent);
+  }catch(_){
+    // Best-effort: older engines have no journal.
+  }
+}
+
 function renderRuntimeEvents(){
   const el = document.querySelector("#runtimeEvents");
   if(!el) return;
@@ -270,9 +311,10 @@ const MIXER_LABEL = {
 // - Controls stay hidden/locked until we receive an authoritative RC snapshot.
 //
 // Data path:
-// - Primary: WebSocket /ws
+// - Primary: WebSocket /ws?meters=u16
 //     * { type: "snapshot", data: { rc: {"101":0.5, ...} } }
 //     * { type: "delta", rc: {"101":0.55, ...} }
+//     * { type: "meter_index", ids: [411, 412, ...] } then binary meter frames
 // - Fallback: one-shot GET /api/state (same rc map)
 //
 // We keep a local copy of the last known RC map strictly for rendering.
@@ -388,6 +430,92 @@ function applyMixerMutesFromRC(){
 //   state (watchdog restart, other UI, CLI, DSP, etc.).
 let _rcWS = null;
 let _rcWSBackoffMs = 500;
+// Meter RC ids in binary frame order (from the engine's "meter_index" message).
+let _rcWSMeterIndex = [];
+
+// Meter RC ids -> StudioStatus meter keys.
+const METER_KEY_BY_RC = { 411: "pgmL", 412: "pgmR", 460: "spkL", 461: "spkR", 462: "rsrL", 463: "rsrR" };
+// Fader lanes that show engine meters (L, R). Other lanes keep the placeholder
+// bars; their marks use the program meter's scale.
+const LANE_METERS = { spk: ["spkL", "spkR"], pgm: ["pgmL", "pgmR"] };
+const DEFAULT_METER_FLOOR_DB = -60;
+
+function laneFloorDb(strip){
+  const keys = LANE_METERS[strip] || LANE_METERS.pgm;
+  const f = Number(state.meterFloorDb[keys[0]]);
+  return (Number.isFinite(f) && f < 0) ? f : DEFAULT_METER_FLOOR_DB;
+}
+
+// Fraction of the lane height (0 = bottom) for a dBFS value on a scale that
+// runs linearly in dB from floor (bottom) to 0 dBFS (top).
+function dbToLane(db, floor){
+  if(!Number.isFinite(db)) return 0;
+  return clamp01((db - floor) / -floor);
+}
+
+// Place every lane's dB marks on its meter scale, so -12 and 0 sit exactly
+// where the Core's meter blocks put them.
+function positionFaderMarks(){
+  document.querySelectorAll('.fader__mark[data-db]').forEach(mark=>{
+    const strip = mark.closest('[data-strip]')?.getAttribute('data-strip');
+    const floor = laneFloorDb(strip);
+    const raw = mark.getAttribute('data-db');
+    const db = (raw === 'floor') ? floor : Number(raw);
+    mark.style.top = ((1 - dbToLane(db, floor)) * 100).toFixed(1) + "%";
+  });
+}
+
+// Fill the metered lanes' L/R bars from the calibrated dBFS levels.
+function renderLaneMeters(){
+  for(const [strip, keys] of Object.entries(LANE_METERS)){
+    const lane = document.querySelector(`[data-strip="${strip}"]`);
+    if(!lane) continue;
+    const floor = laneFloorDb(strip);
+    ['L', 'R'].forEach((side, i)=>{
+      const bar = lane.querySelector(`.fader__meter--${side}`);
+      if(!bar) return;
+      const db = state.meterDb[keys[i]];
+      const frac = dbToLane(Number(db), floor);
+      bar.style.clipPath = `inset(${((1 - frac) * 100).toFixed(1)}% 0 0 0)`;
+    });
+  }
+}
+
+// Decode one binary meter frame into the RC cache.
+// Layout (little endian): 'M', encoding (1=u16, 2=f32), count u16, t u64,
+// then count levels and (engine v0.3.60+) count held peaks.
+// Engine v0.3.61+ appends level/peak dBFS sections (see meter_index.sections);
+// the level dBFS section drives the fader lane bars (u16 frames carry int16
+// centi-dB). Levels/peaks are already ballistics-processed by the engine; do
+// not smooth again.
+function applyMeterFrame(buf){
+  const dv = new DataView(buf);
+  if(dv.byteLength < 12 || dv.getUint8(0) !== 0x4D) return;
+  const enc = dv.getUint8(1);
+  const n = Math.min(dv.getUint16(2, true), _rcWSMeterIndex.length);
+  const width = (enc === 2) ? 4 : 2;
+  state.rc = state.rc || {};
+  for(let i = 0; i < n; i++){
+    const off = 12 + i * width;
+    if(off + width > dv.byteLength) break;
+    const v = (enc === 2) ? dv.getFloat32(off, true) : dv.getUint16(off, true) / 65535;
+    state.rc[String(_rcWSMeterIndex[i])] = v;
+  }
+  state.rcPeak = state.rcPeak || {};
+  for(let i = 0; i < n; i++){
+    const off = 12 + (n + i) * width;
+    if(off + width > dv.byteLength) break;
+    const v = (enc === 2) ? dv.getFloat32(off, true) : dv.getUint16(off, true) / 65535;
+    state.rcPeak[String(_rcWSMeterIndex[i])] = v;
+  }
+  for(let i = 0; i < n; i++){
+    const off = 12 + (2 * n + i) * width;
+    if(off + width > dv.byteLength) break;
+    const key = METER_KEY_BY_RC[_rcWSMeterIndex[i]];
+    if(!key) continue;
+    state.meterDb[key] = (enc === 2) ? dv.getFloat32(off, true) : dv.getInt16(off, true) / 100;
+  }
+}
 
 function connectRCWebSocket(){
   // Avoid duplicate sockets.
@@ -397,8 +525,10 @@ function connectRCWebSocket(){
 
   try{
     const proto = (location.protocol === 'https:') ? 'wss:' : 'ws:';
-    const url = `${proto}//${location.host}/ws`;
+    // Opt in to packed uint16 meter frames (v0.3.57); cuts JSON parsing on the tablets.
+    const url = `${proto}//${location.host}/ws?meters=u16`;
     const ws = new WebSocket(url);
+    ws.binaryType = 'arraybuffer';
     _rcWS = ws;
 
     ws.onopen = ()=>{
@@ -408,9 +538,23 @@ function connectRCWebSocket(){
     };
 
     ws.onmessage = (ev)=>{
+      if(ev.data instanceof ArrayBuffer){
+        applyMeterFrame(ev.data);
+        return;
+      }
       let msg = null;
       try{ msg = JSON.parse(ev.data); }catch(_e){ return; }
 
+      if(msg && msg.type === 'event'){
+        addEngineEvent(msg);
+        return;
+      }
+
+      if(msg && msg.type === 'meter_index' && Array.isArray(msg.ids)){
+        _rcWSMeterIndex = msg.ids;
+        return;
+      }
+
       if(msg && msg.type === 'snapshot' && msg.data && msg.data.rc){
         state.rc = msg.data.rc || {};
         state.mixerHydrated = true;
@@ -1151,6 +1295,29 @@ function applyStudioStatus(j){
   state.meters.rsrL.tgt = clamp01(m.rsrL);
   state.meters.rsrR.tgt = clamp01(m.rsrR);
 
+  // dBFS levels (engine v0.3.61+); binary WS frames update them in between.
+  const floors = j?.floorsDb || {};
+  let floorsChanged = false;
+  for(const k of Object.keys(state.meters)){
+    if(Number.isFinite(j?.metersDb?.[k])) state.meterDb[k] = j.metersDb[k];
+    if(Number.isFinite(floors[k]) && state.meterFloorDb[k] !== floors[k]){
+      state.meterFloorDb[k] = floors[k];
+      floorsChanged = true;
+    }
+  }
+  if(floorsChanged) positionFaderMarks();
+
+  // show lock (v0.3.75): frozen controls are refused by the engine (423).
+  const lockPill = $("#showLockPill");
+  if(lockPill){
+    const l = j?.showLock;
+    lockPill.style.display = l ? "" : "none";
+    if(l){
+      lockPill.textContent = "show lock: " + (l.groups?.length ? l.groups.join(", ") : "controls");
+      lockPill.title = `${l.reason || ""}${l.lockedBy ? ` — locked by ${l.lockedBy}` : ""}`;
+    }
+  }
+
   updateSpeakerUI();
   setPills();
 }
@@ -1206,6 +1373,7 @@ function meterAnimate(){
   setMeterFill("m_spkR", state.meters.spkR.cur);
   setMeterFill("m_rsrL", state.meters.rsrL.cur);
   setMeterFill("m_rsrR", state.meters.rsrR.cur);
+  renderLaneMeters();
 
   requestAnimationFrame(meterAnimate);
 }
@@ -1467,17 +1635,114 @@ function wireUI(){
   if(btnReconnect){
     btnReconnect.addEventListener("click", async ()=>{
       const msg = $("#reconnectMsg");
-      if(msg) msg.textContent = "Sending…";
+      if(msg) msg.textContent = "Reconnecting…";
       try{
-        await fetch("/api/reconnect", { method:"POST" });
-        if(msg) msg.textContent = "OK";
-        if(msg) setTimeout(()=>msg.textContent="", 1200);
+        // v0.3.73: the engine returns each step's outcome.
+        const j = await fetchJSON("/api/reconnect", { method:"POST" }, 15000);
+        const bad = (j?.steps || []).find(s => !s.ok);
+        if(msg) msg.textContent = j?.ok ? "OK" : `Failed at ${bad?.step || "?"}: ${bad?.detail || ""}`;
+        if(msg && j?.ok) setTimeout(()=>msg.textContent="", 1200);
       }catch(e){
         if(msg) msg.textContent = "Failed";
       }
     });
   }
 
+// LIVE arm / disarm (v0.3.74). Admin PIN from the Engineering PIN field.
+async function postDSPArm(url, body){
+  const msg = $("#dspArmMsg");
+  const pin = ($("#adminPin")?.value || "").trim();
+  if(msg) msg.textContent = "…";
+  try{
+    const res = await fetch(url, {
+      method: "POST",
+      headers: { "Content-Type":"application/json", "X-Admin-PIN": pin },
+      body: JSON.stringify(body)
+    });
+    const j = await res.json().catch(()=>({}));
+    if(!res.ok) throw new Error(j.error || res.statusText);
+    if(msg) msg.textContent = "OK";
+    if(msg) setTimeout(()=>msg.textContent="", 1200);
+  }catch(e){
+    if(msg) msg.textContent = "Failed: " + e.message;
+  }
+  await fetchDSPModeStatus();
+}
+$("#btnDspArm")?.addEventListener("click", async ()=>{
+  const ttl = prompt("Arm LIVE writes for how many minutes?", "15");
+  if(ttl === null) return;
+  const reason = prompt("Reason for arming LIVE writes:", "");
+  if(!reason) return;
+  await postDSPArm("/api/dsp/arm", { ttlSec: Math.round(Number(ttl) * 60) || 0, reason });
+});
+$("#btnDspDisarm")?.addEventListener("click", ()=>postDSPArm("/api/dsp/disarm", { reason: "operator" }));
+$("#btnDspConformance")?.addEventListener("click", ()=>postDSPArm("/api/dsp/conformance", {}));
+
+// Raw ECP transcript (v0.3.79): toggle capture, export as text for QSC tickets.
+// adminFetch sends the Engineering PIN and throws on a non-2xx reply.
+async function adminFetch(url, opts){
+  const pin = ($("#adminPin")?.value || "").trim();
+  const res = await fetch(url, Object.assign({}, opts, {
+    headers: Object.assign({ "Content-Type":"application/json", "X-Admin-PIN": pin }, (opts && opts.headers) || {})
+  }));
+  if(!res.ok){
+    const j = await res.json().catch(()=>({}));
+    throw new Error(j.error || res.statusText);
+  }
+  return res;
+}
+$("#btnEcpTranscript")?.addEventListener("click", async ()=>{
+  const msg = $("#ecpTranscriptMsg");
+  try{
+    const cur = await (await adminFetch("/api/dsp/transcript?entries=0")).json();
+    const st = await (await adminFetch("/api/dsp/transcript", {
+      method: "POST",
+      body: JSON.stringify({ enabled: !cur.enabled, clear: !cur.enabled })
+    })).json();
+    if(msg) msg.textContent = st.enabled ? "capturing…" : `stopped (${st.count} entries)`;
+  }catch(e){
+    if(msg) msg.textContent = "Failed: " + e.message;
+  }
+});
+$("#btnEcpTranscriptExport")?.addEventListener("click", async ()=>{
+  const msg = $("#ecpTranscriptMsg");
+  try{
+    const blob = await (await adminFetch("/api/dsp/transcript?format=text&download=1")).blob();
+    const a = document.createElement("a");
+    a.href = URL.createObjectURL(blob);
+    a.download = "ecp-transcript.txt";
+    a.click();
+    setTimeout(()=>URL.revokeObjectURL(a.href), 1000);
+  }catch(e){
+    if(msg) msg.textContent = "Failed: " + e.message;
+  }
+});
+
+// Admin ECP console (v0.3.80). Writes (csv/csp) must be retyped to confirm.
+async function sendEcpConsole(){
+  const out = $("#ecpConsoleOut");
+  const command = ($("#ecpConsoleCmd")?.value || "").trim();
+  if(!command || !out) return;
+  const body = { command, source: "ui" };
+  if(/^(csv|csp)\s/i.test(command)){
+    const confirm = prompt("This WRITES to the Core. Retype the command to confirm:", "");
+    if(confirm === null) return;
+    body.confirm = confirm.trim();
+  }
+  out.style.display = "";
+  try{
+    const res = await adminFetch("/api/dsp/console", { method: "POST", body: JSON.stringify(body) });
+    const j = await res.json();
+    const r = j.result || {};
+    out.textContent = `> ${r.command}  (${r.target || "?"}, ${r.tookMs} ms)\n${r.reply || r.error || "—"}`
+      + (r.parsed ? "\n" + JSON.stringify(r.parsed, null, 2) : "");
+  }catch(e){
+    out.textContent = `> ${command}\nrefused: ${e.message}`;
+  }
+}
+$("#btnEcpConsole")?.addEventListener("click", sendEcpConsole);
+$("#ecpConsoleCmd")?.addEventListener("keydown", (ev)=>{ if(ev.key === "Enter") sendEcpConsole(); });
+
 // Manual "Test DSP Now" (single-shot). This is the ONLY place the UI triggers
 // DSP network activity, and only on explicit operator request.
 // The entire DSP Health panel can be removed from the Studio page; guard accordingly.
@@ -2509,7 +2774,8 @@ function initPlayItLiveControls(){
 
 document.addEventListener("DOMContentLoaded", ()=>{
   // Runtime event timeline (v0.3.12)
-  addRuntimeEvent(`UI loaded (v${UI_BUILD_VERSION})`);
+  // Seed engine history first so it sorts before this page load.
+  seedRuntimeEventsFromJournal().finally(()=> addRuntimeEvent(`UI loaded (v${UI_BUILD_VERSION})`));
 
 
   // Mixer hydration (v0.3.30): connect to RC WebSocket and wait for an
@@ -2565,6 +2831,35 @@ function renderWatchdogDSP(){
   modeEl.textContent = (m.mode || "—");
           const am = $("#wdDspActiveMode");
           if(am) am.textContent = (m.activeMode || "—");
+  // v0.3.74: LIVE writes are armed for a limited time.
+  const armEl = $("#wdDspArmed");
+  if(armEl){
+    const a = m.arm || {};
+    armEl.textContent = a.armed
+      ? `until ${a.expiresAt ? new Date(a.expiresAt).toLocaleTimeString() : "?"} (${a.reason || "—"})`
+      : "no";
+  }
+  // v0.3.77: canary rollout — only these controls write to the DSP in LIVE.
+  const lcEl = $("#wdDspLiveControls");
+  if(lcEl){
+    const lc = m.liveControls || [];
+    lcEl.textContent = lc.length
+      ? lc.map(c => `${c.ecpName || "?"} (${c.rc})${c.error ? " ⚠" : ""}`).join(", ")
+      : "none";
+  }
+  // v0.3.81: design conformance — failures block arming.
+  const dcEl = $("#wdDspConformance");
+  if(dcEl){
+    const c = m.conformance;
+    if(!c){
+      dcEl.textContent = "not run";
+    }else if(c.ok){
+      dcEl.textContent = `OK (${c.checked} controls${c.unknown && c.unknown.length ? `, ${c.unknown.length} unknown` : ""})`;
+    }else{
+      const bad = (c.controls || []).filter(x => x.status !== "ok" && x.status !== "unnamed");
+      dcEl.textContent = `FAILED: ` + bad.map(x => `${x.name} ${x.status}`).join(", ");
+    }
+  }
   $("#wdDspState").textContent = (h.state || "—");
   $("#wdDspLastTest").textContent = (h.lastTestAt || "—");
   const wlp = $("#wdDspLastPoll");
@@ -2581,7 +2876,10 @@ function renderWatchdogDSP(){
       const val = (typeof lw.value === "number") ? lw.value : "—";
       const ts = lw.ts || "—";
       const err = lw.error ? ` (${lw.error})` : "";
-      lwEl.textContent = `${ts}  ${lw.name}=${val}  ${ok}${err}`;
+      // v0.3.78: shadow mode — show the exact command that was NOT sent.
+      lwEl.textContent = lw.dryRun
+        ? `${ts}  DRY RUN  ${lw.command || (lw.name + "=" + val)} → ${lw.target || "—"}`
+        : `${ts}  ${lw.name}=${val}  ${ok}${err}`;
     }
   }
 